@@ -0,0 +1,167 @@
+// This file content implementation of methods to check CISCO ASA AAA servers
+// (RADIUS, LDAP, TACACS+) reachability
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckAAA check Cisco ASA AAA servers state and timeouts
+func (asa *CiscoASA) CheckAAA(host string, username string, password string, identity string, port int, critical string, warning string, stateDir string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show aaa-server\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseAAA(stdout, stateFile(stateDir, host, "aaa.json"), critical, warning)
+}
+
+// ParseAAA parse the output of show aaa-server and return Icinga result depending critical and warning
+// thresholds, timeouts are compared with the previous run
+func (asa *CiscoASA) ParseAAA(response string, file string, critical string, warning string) (ict.Icinga, error) {
+
+	var reField = regexp.MustCompile(`(?i)^\s*(?P<name>Server Group|Server Protocol|Server Address|Server status|Average round trip time|Number of (?:authentication|authorization|accounting) requests|Number of rejects|Number of timeouts):?\s+(?P<value>.+?)\s*$`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	commands := []string{"show aaa-server"}
+	if err := checkOutput(response, commands); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for each server, a new server begin with its group name
+	var servers []map[string]string
+	for _, line := range strings.Split(strings.Replace(response, "\r", "", -1), "\n") {
+		m := reField.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := strings.ToLower(m[1])
+		if name == "server group" {
+			servers = append(servers, map[string]string{"requests": "0"})
+		}
+		if len(servers) == 0 {
+			continue
+		}
+		server := servers[len(servers)-1]
+		switch {
+		case name == "server group":
+			server["group"] = m[2]
+		case name == "server protocol":
+			server["protocol"] = m[2]
+		case name == "server address":
+			server["address"] = m[2]
+		case name == "server status":
+			if status := strings.Fields(strings.Replace(m[2], ",", " ", -1)); len(status) > 0 {
+				server["status"] = strings.ToUpper(status[0])
+			}
+		case name == "average round trip time":
+			server["rtt"] = strings.TrimSuffix(m[2], "ms")
+		case strings.HasSuffix(name, "requests"):
+			requests, _ := strconv.Atoi(server["requests"])
+			count, _ := strconv.Atoi(m[2])
+			server["requests"] = strconv.Itoa(requests + count)
+		case name == "number of rejects":
+			server["rejects"] = m[2]
+		case name == "number of timeouts":
+			server["timeouts"] = m[2]
+		}
+	}
+
+	if len(servers) == 0 {
+		return ict.Icinga{}, missingError(response, commands[0], "Server Group")
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	// Counting failed servers by group, a group without active server is critical
+	failed := make(map[string]int)
+	total := make(map[string]int)
+	for _, server := range servers {
+		total[server["group"]]++
+		if server["status"] == "FAILED" {
+			failed[server["group"]]++
+		}
+	}
+
+	// Comparing timeouts with the previous run, counters are reset on reload
+	timeouts := make(map[string]int)
+	for _, server := range servers {
+		count, _ := strconv.Atoi(server["timeouts"])
+		timeouts[fmt.Sprintf("%s/%s", server["group"], server["address"])] = count
+	}
+	deltas, err := counterDeltas(file, timeouts)
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	for _, server := range servers {
+		name := fmt.Sprintf("%s/%s", server["group"], server["address"])
+
+		if server["status"] == "FAILED" {
+			if failed[server["group"]] == total[server["group"]] {
+				condition = ict.CriExit
+			} else if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("%s FAILED", name))
+		}
+
+		if newTimeouts := deltas[name]; newTimeouts > 0 {
+			if errCritical == nil && criticalTH.AAATimeouts > 0 && newTimeouts > criticalTH.AAATimeouts {
+				condition = ict.CriExit
+				message = appendMessage(message, fmt.Sprintf("%s %d new timeouts", name, newTimeouts))
+			} else if errWarning != nil || newTimeouts > warningTH.AAATimeouts {
+				if condition < ict.WarExit {
+					condition = ict.WarExit
+				}
+				message = appendMessage(message, fmt.Sprintf("%s %d new timeouts", name, newTimeouts))
+			}
+		}
+
+		// Setting server metrics
+		if server["rtt"] != "" {
+			metrics += fmt.Sprintf("'%s rtt'=%sms ", name, server["rtt"])
+		}
+		metrics += fmt.Sprintf("'%s requests'=%sc ", name, server["requests"])
+		if server["rejects"] != "" {
+			metrics += fmt.Sprintf("'%s rejects'=%sc ", name, server["rejects"])
+		}
+		metrics += fmt.Sprintf("'%s timeouts'=%dc ", name, timeouts[name])
+
+		details += fmt.Sprintf("\n%s (%s) %s", name, server["protocol"], server["status"])
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, server := range servers {
+			log.Printf("%s %s %s - %s - rtt %sms - requests %s - rejects %s - timeouts %s\n", server["group"], server["protocol"], server["address"], server["status"], server["rtt"], server["requests"], server["rejects"], server["timeouts"])
+		}
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("%d AAA servers in %d groups Ok", len(servers), len(total))
+	}
+	return ict.Icinga{Message: message + details, Exit: condition, Metric: metrics}, nil
+}
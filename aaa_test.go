@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	AAAServerResponse = `asa# show aaa-server
+Server Group:    RADIUS-VPN
+Server Protocol: radius
+Server Hostname: 10.1.1.20
+Server Address:  10.1.1.20
+Server port:     1645(authentication), 1646(accounting)
+Server status:   ACTIVE, Last transaction at 14:05:22 UTC Tue Mar 15 2016
+Number of pending requests              0
+Average round trip time                 12ms
+Number of authentication requests       1543
+Number of authorization requests        0
+Number of accounting requests           57
+Number of retransmissions               2
+Number of accepts                       1400
+Number of rejects                       140
+Number of challenges                    0
+Number of malformed responses           0
+Number of bad authenticators            0
+Number of timeouts                      3
+Number of unrecognized responses        0
+
+Server Group:    RADIUS-VPN
+Server Protocol: radius
+Server Hostname: 10.1.1.21
+Server Address:  10.1.1.21
+Server port:     1645(authentication), 1646(accounting)
+Server status:   FAILED, Server disabled at 13:55:02 UTC Tue Mar 15 2016
+Number of pending requests              0
+Average round trip time                 0ms
+Number of authentication requests       12
+Number of authorization requests        0
+Number of accounting requests           0
+Number of retransmissions               36
+Number of accepts                       0
+Number of rejects                       0
+Number of challenges                    0
+Number of malformed responses           0
+Number of bad authenticators            0
+Number of timeouts                      12
+Number of unrecognized responses        0
+
+Server Group:    LDAP-AD
+Server Protocol: ldap
+Server Hostname: dc01.example.com
+Server Address:  10.1.1.30
+Server port:     389
+Server status:   ACTIVE, Last transaction at 14:06:01 UTC Tue Mar 15 2016
+Number of pending requests              0
+Average round trip time                 4ms
+Number of authentication requests       0
+Number of authorization requests        810
+Number of accounting requests           0
+Number of retransmissions               0
+Number of accepts                       810
+Number of rejects                       0
+Number of challenges                    0
+Number of malformed responses           0
+Number of bad authenticators            0
+Number of timeouts                      0
+Number of unrecognized responses        0
+asa# `
+)
+
+func TestCiscoASA_ParseAAA(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	file := filepath.Join(stateDir, "asa_aaa.json")
+
+	icinga, err := asa.ParseAAA(AAAServerResponse, file, `{}`, `{}`)
+	if err != nil || icinga.Exit != ict.WarExit {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.WarExit, icinga.Exit, icinga, err)
+	}
+	want := "RADIUS-VPN/10.1.1.21 FAILED\nRADIUS-VPN/10.1.1.20 (radius) ACTIVE\nRADIUS-VPN/10.1.1.21 (radius) FAILED\nLDAP-AD/10.1.1.30 (ldap) ACTIVE"
+	if icinga.Message != want {
+		t.Errorf("Error want message\n%s\ngot\n%s", want, icinga.Message)
+	}
+	if !strings.Contains(icinga.Metric, "'RADIUS-VPN/10.1.1.20 rtt'=12ms 'RADIUS-VPN/10.1.1.20 requests'=1600c 'RADIUS-VPN/10.1.1.20 rejects'=140c 'RADIUS-VPN/10.1.1.20 timeouts'=3c ") {
+		t.Errorf("Error unexpected metrics %s", icinga.Metric)
+	}
+
+	// All servers of the group failed and timeouts increased
+	failed := strings.Replace(AAAServerResponse, "ACTIVE, Last transaction at 14:05:22", "FAILED, Server disabled at 14:10:22", 1)
+	failed = strings.Replace(failed, "Number of timeouts                      3", "Number of timeouts                      9", 1)
+	icinga, _ = asa.ParseAAA(failed, file, `{"aaa_timeouts":10}`, `{}`)
+	if icinga.Exit != ict.CriExit || !strings.HasPrefix(icinga.Message, "RADIUS-VPN/10.1.1.20 FAILED/RADIUS-VPN/10.1.1.20 6 new timeouts/RADIUS-VPN/10.1.1.21 FAILED\n") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Unrecognized output is an error with the offending line
+	if _, err = asa.ParseAAA("asa# show aaa-server\nNo AAA server configured\nasa# ", file, `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "Server Group not found in show aaa-server => No AAA server configured") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
+
+func TestCheck_AAA(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckAAA(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+	if err != nil {
+		t.Errorf("Error CheckAAA: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,171 @@
+// This file content implementation of methods to check CISCO ASA access lists hit counts
+// and report unused rules
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// aclBaseline is the hit count of an ACE when it was first seen
+type aclBaseline struct {
+	Since time.Time `json:"since"`
+	Hits  int       `json:"hits"`
+}
+
+// aclState is the state kept between two executions of the acl check
+type aclState struct {
+	Time     time.Time              `json:"time"`
+	Hits     map[string]int         `json:"hits"`
+	Baseline map[string]aclBaseline `json:"baseline"`
+}
+
+// CheckACL check Cisco ASA access list hit rates or report rules without hits
+func (asa *CiscoASA) CheckACL(host string, username string, password string, identity string, port int, critical string, warning string, stateDir string, rules []string, report bool, accept bool) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show access-list\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseACL(stdout, stateFile(stateDir, host, "acl.json"), time.Now(), critical, warning, rules, report, accept)
+}
+
+// ParseACL parse the output of show access-list, in monitoring mode hit rates of rules matching one of rules
+// patterns (all deny rules by default) are compared to critical and warning thresholds, in report mode
+// rules without hits since the baseline are listed
+func (asa *CiscoASA) ParseACL(response string, file string, now time.Time, critical string, warning string, rules []string, report bool, accept bool) (ict.Icinga, error) {
+
+	var reACE = regexp.MustCompile(`(?mi)^access-list (?P<acl>\S+) line (?P<line>\d+) (?P<ace>.+?) \(hitcnt=(?P<hits>\d+)\)\s*(?P<hash>0x[0-9a-f]+)?\s*$`)
+	var reDeny = regexp.MustCompile(`(?i)^\S+ deny `)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+	var state aclState
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	var reRules []*regexp.Regexp
+	for _, pattern := range rules {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return ict.Icinga{}, fmt.Errorf("ParseACL, %w, invalid rule pattern %s: %s", errConfig, pattern, err)
+		}
+		reRules = append(reRules, re)
+	}
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for ACEs, expanded object-group entries (indented) are ignored
+	keys := reACE.SubexpNames()[1:]
+	var aces []map[string]string
+	for _, s := range reACE.FindAllStringSubmatch(response, -1) {
+		ace := make(map[string]string)
+		for i, v := range s[1:] {
+			ace[keys[i]] = v
+		}
+		// ACE hash is kept when ACE lines are renumbered
+		ace["key"] = ace["hash"]
+		if ace["key"] == "" {
+			ace["key"] = fmt.Sprintf("%s %s", ace["acl"], ace["ace"])
+		}
+		aces = append(aces, ace)
+	}
+	if len(aces) == 0 {
+		return ict.Icinga{Message: "Access list entries not found", Exit: ict.UnkExit}, nil
+	}
+
+	if err := loadState(file, &state); err != nil {
+		return ict.Icinga{}, err
+	}
+	if accept || state.Baseline == nil {
+		state.Baseline = make(map[string]aclBaseline)
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	elapsed := now.Sub(state.Time).Minutes()
+	hits := make(map[string]int)
+	baseline := make(map[string]aclBaseline)
+	unused := 0
+	for _, ace := range aces {
+		count, _ := strconv.Atoi(ace["hits"])
+		name := fmt.Sprintf("%s line %s", ace["acl"], ace["line"])
+		hits[ace["key"]] = count
+
+		// New ACEs are added to baseline, removed ACEs are forgotten
+		base, known := state.Baseline[ace["key"]]
+		if !known {
+			base = aclBaseline{Since: now, Hits: count}
+		}
+		baseline[ace["key"]] = base
+
+		if report {
+			// Hit counters are reset on reload or clear access-list
+			if known && counterIncrease(base.Hits, count) == 0 {
+				unused++
+				details += fmt.Sprintf("\n%s %s - no hits since %s", name, ace["ace"], base.Since.Format("02 January 2006 15:04:05"))
+			}
+			continue
+		}
+
+		// Monitoring designated rules, all deny rules by default
+		designated := len(reRules) == 0 && reDeny.MatchString(ace["ace"])
+		for _, re := range reRules {
+			if re.MatchString(fmt.Sprintf("%s %s", name, ace["ace"])) {
+				designated = true
+				break
+			}
+		}
+		previous, seen := state.Hits[ace["key"]]
+		if !designated || !seen || elapsed <= 0 {
+			continue
+		}
+		rate := float64(counterIncrease(previous, count)) / elapsed
+
+		if errCritical == nil && criticalTH.ACLRate > 0 && rate > criticalTH.ACLRate {
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("%s %.1f hits/min > %.1f", name, rate, criticalTH.ACLRate))
+		} else if errWarning == nil && warningTH.ACLRate > 0 && rate > warningTH.ACLRate {
+			if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("%s %.1f hits/min > %.1f", name, rate, warningTH.ACLRate))
+		}
+		metrics += fmt.Sprintf("'%s rate'=%.2f ", name, rate)
+	}
+
+	if err := saveState(file, aclState{Time: now, Hits: hits, Baseline: baseline}); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, ace := range aces {
+			log.Printf("%s line %s - %s - hitcnt %s - %s\n", ace["acl"], ace["line"], ace["ace"], ace["hits"], ace["hash"])
+		}
+	}
+
+	if report {
+		message = fmt.Sprintf("%d of %d access list entries without hits since baseline", unused, len(aces))
+		return ict.Icinga{Message: message + details, Exit: ict.OkExit, Metric: fmt.Sprintf("'Unused entries'=%d ", unused)}, nil
+	}
+	if message == "" {
+		message = fmt.Sprintf("%d access list entries checked", len(aces))
+	}
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
+}
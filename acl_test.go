@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	ACLResponse = `asa# show access-list
+access-list cached ACL log flows: total 0, denied 0 (deny-flow-max 4096)
+            alert-interval 300
+access-list outside_in; 3 elements; name hash: 0x6892a938
+access-list outside_in line 1 extended permit tcp any host 192.0.2.10 eq https (hitcnt=1500) 0x1a2b3c4d
+access-list outside_in line 2 extended permit tcp any object-group WEB eq www (hitcnt=20) 0x5e6f7a8b
+  access-list outside_in line 2 extended permit tcp any host 192.0.2.11 eq www (hitcnt=20) 0x11111111
+access-list outside_in line 3 remark Legacy FTP server
+access-list outside_in line 4 extended permit tcp any host 192.0.2.12 eq ftp (hitcnt=0) 0x9c0d1e2f
+access-list outside_in line 5 extended deny ip any any log (hitcnt=300) 0x3a4b5c6d
+asa# `
+)
+
+func TestCiscoASA_ParseACL(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	file := filepath.Join(stateDir, "asa_acl.json")
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// First run store hit counts and baseline
+	icinga, err := asa.ParseACL(ACLResponse, file, now, `{"acl_rate":100}`, `{"acl_rate":10}`, nil, false, false)
+	if err != nil || icinga.Exit != ict.OkExit || icinga.Message != "4 access list entries checked" {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.OkExit, icinga.Exit, icinga, err)
+	}
+
+	// 300 deny hits in 10 minutes
+	response := strings.Replace(ACLResponse, "log (hitcnt=300)", "log (hitcnt=600)", 1)
+	icinga, err = asa.ParseACL(response, file, now.Add(10*time.Minute), `{"acl_rate":100}`, `{"acl_rate":10}`, nil, false, false)
+	if err != nil || icinga.Exit != ict.WarExit {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.WarExit, icinga.Exit, icinga, err)
+	}
+	if icinga.Message != "outside_in line 5 30.0 hits/min > 10.0" || icinga.Metric != "'outside_in line 5 rate'=30.00 " {
+		t.Errorf("Error unexpected result %s", icinga)
+	}
+
+	// Designated rule
+	response = strings.Replace(response, "https (hitcnt=1500)", "https (hitcnt=3500)", 1)
+	icinga, _ = asa.ParseACL(response, file, now.Add(20*time.Minute), `{"acl_rate":100}`, `{"acl_rate":10}`, []string{`eq https`}, false, false)
+	if icinga.Exit != ict.CriExit || icinga.Metric != "'outside_in line 1 rate'=200.00 " {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Report rules without hits since baseline
+	icinga, err = asa.ParseACL(response, file, now.Add(30*time.Minute), `{}`, `{}`, nil, true, false)
+	if err != nil || icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.OkExit, icinga.Exit, icinga, err)
+	}
+	want := "2 of 4 access list entries without hits since baseline\noutside_in line 2 extended permit tcp any object-group WEB eq www - no hits since 01 June 2020 12:00:00\noutside_in line 4 extended permit tcp any host 192.0.2.12 eq ftp - no hits since 01 June 2020 12:00:00"
+	if icinga.Message != want {
+		t.Errorf("Error want message\n%s\ngot\n%s", want, icinga.Message)
+	}
+
+	// Accepting a new baseline
+	icinga, _ = asa.ParseACL(response, file, now.Add(40*time.Minute), `{}`, `{}`, nil, true, true)
+	if !strings.HasPrefix(icinga.Message, "0 of 4 ") {
+		t.Errorf("Error unexpected message %s", icinga.Message)
+	}
+
+	if _, err = asa.ParseACL(ACLResponse, file, now, `{}`, `{}`, []string{`(`}, false, false); !errors.Is(err, errConfig) {
+		t.Errorf("Error want %s got %v", errConfig, err)
+	}
+	icinga, _ = asa.ParseACL("asa# ", file, now, `{}`, `{}`, nil, false, false)
+	if icinga.Exit != ict.UnkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.UnkExit, icinga.Exit, icinga)
+	}
+}
+
+func TestCheck_ACL(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckACL(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir, params.rules, params.report, params.accept)
+	if err != nil {
+		t.Errorf("Error CheckACL: %s", err)
+	}
+	t.Log(icinga)
+}
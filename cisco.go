@@ -3,6 +3,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -17,14 +18,49 @@ import (
 )
 
 type CiscoASA struct {
-	Name string
+	Name   string
+	Enable string // Enable password, sent only if the ASA ask it
+	Agent  bool   // Authenticate with keys of SSH agent
+
+	KnownHosts string // OpenSSH known_hosts file used to verify the ASA host key
+	HostKey    string // Pinned fingerprint of the ASA host key
+	TOFU       bool   // Record unknown host keys in known_hosts file (trust on first use)
+
+	ConnectTimeout time.Duration // Maximum time to connect, authenticate and open the shell
+	CommandTimeout time.Duration // Maximum wait for the output of each command
+	Timeout        time.Duration // Maximum duration of the whole session
+
+	Retries    int           // Number of retries of connection failures
+	RetryDelay time.Duration // Delay before first retry, doubled at each retry
+
+	Jumps []JumpHost // Jump hosts the connection is tunneled through, in hop order
+
+	SNMP SNMPConfig // SNMP agent configuration used by the SNMP transport
 }
 
 type Threshold struct {
-	CPU            []int `json:"cpu,omitempty"`
-	Memory         int   `json:"memory,omitempty"`
-	UsersVPN       int   `json:"users_vpn,omitempty"`
-	FailoverActive int   `json:"failover_active,omitempty"`
+	CPU            []int   `json:"cpu,omitempty"`
+	Memory         int     `json:"memory,omitempty"`
+	UsersVPN       int     `json:"users_vpn,omitempty"`
+	FailoverActive int     `json:"failover_active,omitempty"`
+	ThreatRate     []int   `json:"threat_rate,omitempty"`
+	ShunnedHosts   int     `json:"shunned_hosts,omitempty"`
+	NTPOffset      float64 `json:"ntp_offset,omitempty"`
+	ClockDrift     int     `json:"clock_drift,omitempty"`
+	UnsavedTime    int     `json:"unsaved_time,omitempty"`
+	ReloadUptime   int     `json:"reload_uptime,omitempty"`
+	Uptime         int     `json:"uptime,omitempty"`
+	CrashHold      int     `json:"crash_hold,omitempty"`
+	DiskFree       int     `json:"disk_free,omitempty"`
+	CoreFiles      int     `json:"core_files,omitempty"`
+	LogDropped     int     `json:"log_dropped,omitempty"`
+	AAATimeouts    int     `json:"aaa_timeouts,omitempty"`
+	TrackChanges   int     `json:"track_changes,omitempty"`
+	PrimaryTracks  []int   `json:"primary_tracks,omitempty"`
+	DHCPPool       int     `json:"dhcp_pool,omitempty"`
+	ACLRate        float64 `json:"acl_rate,omitempty"`
+	InspectDrop    float64 `json:"inspect_drop,omitempty"`
+	TrafficUsage   int     `json:"traffic_usage,omitempty"`
 }
 
 // Instantiate a new CiscoASA
@@ -34,10 +70,158 @@ func NewCiscoASA(name string) *CiscoASA {
 	return ca
 }
 
+// sendCommands open a ssh session to the Cisco ASA, enter privileged mode, disable paging
+// and return the output of commands, if a command times out the output received so far is
+// returned with the error
+func (asa *CiscoASA) sendCommands(host string, username string, password string, identity string, port int, commands []string) (string, error) {
+
+	ctx := context.Background()
+	if asa.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, asa.Timeout)
+		defer cancel()
+	}
+
+	session, err := asa.openSessionRetry(ctx, host, username, password, identity, port)
+	if err != nil {
+		return "", err
+	}
+	defer session.close()
+
+	if err = session.enable(asa.Enable); err != nil {
+		return "", err
+	}
+	for _, command := range append([]string{"terminal pager 0\n"}, commands...) {
+		if err = session.run(command); err != nil {
+			return session.stdout, err
+		}
+	}
+
+	// All commands are sent even if one is rejected so output of other commands stay usable
+	rejected := rejectedCommands(session.stdout)
+	for _, command := range commands {
+		command = strings.TrimSpace(command)
+		if line, found := rejected[command]; found && !optionalCommands[command] {
+			return session.stdout, rejectedError(command, line)
+		}
+	}
+	return session.stdout, nil
+}
+
+// optionalCommands may be rejected by the ASA depending on hardware or installed modules
+var optionalCommands = map[string]bool{
+	"show module sfr details": true,
+	"show service-policy sfr": true,
+}
+
+// reRejected match the error lines printed by the ASA when a command is rejected
+var reRejected = regexp.MustCompile(`^\s*(ERROR:|% Invalid input|Command authorization failed)`)
+
+// rejectedCommands return the commands rejected by the ASA with the first error line of their output
+func rejectedCommands(stdout string) map[string]string {
+	var rePrompt = regexp.MustCompile(`^[\w\-./()]+[#>]`)
+	rejected := make(map[string]string)
+
+	command := ""
+	for _, line := range strings.Split(strings.Replace(stdout, "\r", "", -1), "\n") {
+		if rePrompt.MatchString(line) {
+			command = strings.TrimSpace(rePrompt.ReplaceAllString(line, ""))
+			continue
+		}
+		if _, found := rejected[command]; !found && command != "" && reRejected.MatchString(line) {
+			rejected[command] = strings.TrimSpace(line)
+		}
+	}
+	return rejected
+}
+
+// rejectedError return the error of a command rejected by the ASA with the offending line
+func rejectedError(command string, line string) error {
+	return fmt.Errorf("%w, %s => %s", errUnsupported, command, line)
+}
+
+// section is a part of a command output a check can't be evaluated without
+type section struct {
+	command string
+	name    string
+	re      *regexp.Regexp
+}
+
+// checkOutput return an error with the offending line if a command was rejected by the ASA or if a section
+// is missing in its output, so a different ASA version never give a false OK
+func checkOutput(response string, commands []string, sections ...section) error {
+	rejected := rejectedCommands(response)
+	for _, command := range commands {
+		if line, found := rejected[command]; found && !optionalCommands[command] {
+			return rejectedError(command, line)
+		}
+	}
+
+	for _, s := range sections {
+		if !s.re.MatchString(strings.Join(commandOutput(response, s.command), "\n")) {
+			return missingError(response, s.command, s.name)
+		}
+	}
+	return nil
+}
+
+// missingError return the parse error of a section name not found in the output of command, with the first
+// line of the output
+func missingError(response string, command string, name string) error {
+	line := "empty output"
+	for _, l := range commandOutput(response, command) {
+		if strings.TrimSpace(l) != "" {
+			line = strings.TrimSpace(l)
+			break
+		}
+	}
+	return fmt.Errorf("%w, %s not found in %s => %s", errParse, name, command, line)
+}
+
+// commandOutput return the lines printed by command in the ssh session output,
+// lines between the command echo and the next prompt
+func commandOutput(stdout string, command string) []string {
+	var rePrompt = regexp.MustCompile(`^[\w\-./()]+[#>]`)
+	var output []string
+
+	found := false
+	for _, line := range strings.Split(strings.Replace(stdout, "\r", "", -1), "\n") {
+		if rePrompt.MatchString(line) {
+			if found {
+				break
+			}
+			found = strings.TrimSpace(rePrompt.ReplaceAllString(line, "")) == command
+			continue
+		}
+		if found {
+			output = append(output, line)
+		}
+	}
+	return output
+}
+
+// appendMessage add text to message using "/" as separator
+func appendMessage(message string, text string) string {
+	if message != "" {
+		message += "/"
+	}
+	return message + text
+}
+
 // CheckStatus check Cisco ASA environment conditions
 func (asa *CiscoASA) CheckStatus(host string, username string, password string, identity string, port int, critical string, warning string) (ict.Icinga, error) {
 
-	var ssh *ict.SSHTools
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show environment\n", "show cpu\n", "show mem\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseStatus(stdout, critical, warning)
+}
+
+// ParseStatus parse environment, CPU and memory usage and return Icinga result depending critical and warning thresholds,
+// an error is returned if CPU or memory usage is not found
+func (asa *CiscoASA) ParseStatus(response string, critical string, warning string) (ict.Icinga, error) {
 
 	var reCooling = regexp.MustCompile(`(?mi)^\s*cooling Fan\s+(?P<number>\d+)\s*:\s+(?P<rpm>\d+)\s+RPM\s+-\s+(?P<status>.+)$`)
 	var reCPUTemp = regexp.MustCompile(`(?mi)^\s*Processor\s+(?P<number>\d+):\s*(?P<temp>\d+\.\d)\s+C\s+-\s+(?P<status>[^\s]*).*$`)
@@ -48,14 +232,9 @@ func (asa *CiscoASA) CheckStatus(host string, username string, password string,
 	var warningTH Threshold
 	var criticalTH Threshold
 
-	// Opening a ssh session to the cisco ASA
-	ssh, err = ict.NewSSHTools(host, username, password, identity, port)
-	if err != nil {
-		return ict.Icinga{}, err
-	}
-
-	// Sending commands to the Cisco ASA and getting returned data
-	err = ssh.SendSSHhasPTY([]string{"enable\n\n", "terminal pager 0\n", "show environment\n", "show cpu\n", "show mem\n"}, `(?i)^(.*\>.?)|(.*\#.?)|(Password:.?)$`)
+	// Environment sensors are missing on virtual ASA
+	err := checkOutput(response, []string{"show environment", "show cpu", "show mem"},
+		section{"show cpu", "CPU utilization", reCPU}, section{"show mem", "Free memory", reMem})
 	if err != nil {
 		return ict.Icinga{}, err
 	}
@@ -66,7 +245,7 @@ func (asa *CiscoASA) CheckStatus(host string, username string, password string,
 	// Creating map for cooling conditions
 	keys := reCooling.SubexpNames()[1:]
 	var rpmCooling []map[string]string
-	for _, s := range reCooling.FindAllStringSubmatch(ssh.Stdout, -1) {
+	for _, s := range reCooling.FindAllStringSubmatch(response, -1) {
 		cooling := make(map[string]string)
 		for i, v := range s[1:] {
 			cooling[keys[i]] = v
@@ -77,7 +256,7 @@ func (asa *CiscoASA) CheckStatus(host string, username string, password string,
 	// Creating map for CPU temperature
 	keys = reCPUTemp.SubexpNames()[1:]
 	var tempCPU []map[string]string
-	for _, s := range reCPUTemp.FindAllStringSubmatch(ssh.Stdout, -1) {
+	for _, s := range reCPUTemp.FindAllStringSubmatch(response, -1) {
 		cpu := make(map[string]string)
 		for i, v := range s[1:] {
 			cpu[keys[i]] = v
@@ -88,7 +267,7 @@ func (asa *CiscoASA) CheckStatus(host string, username string, password string,
 	// Creating map for ambient temperature
 	keys = reAmbient.SubexpNames()[1:]
 	var tempAmbient []map[string]string
-	for _, s := range reAmbient.FindAllStringSubmatch(ssh.Stdout, -1) {
+	for _, s := range reAmbient.FindAllStringSubmatch(response, -1) {
 		ambient := make(map[string]string)
 		for i, v := range s[1:] {
 			ambient[keys[i]] = v
@@ -99,7 +278,7 @@ func (asa *CiscoASA) CheckStatus(host string, username string, password string,
 	// Creating map for CPU usage
 	keys = reCPU.SubexpNames()[1:]
 	var usageCPU []map[string]string
-	for _, s := range reCPU.FindAllStringSubmatch(ssh.Stdout, -1) {
+	for _, s := range reCPU.FindAllStringSubmatch(response, -1) {
 		cpu := make(map[string]string)
 		for i, v := range s[1:] {
 			cpu[keys[i]] = v
@@ -110,7 +289,7 @@ func (asa *CiscoASA) CheckStatus(host string, username string, password string,
 	// Creating map for free memory information
 	keys = reMem.SubexpNames()[1:]
 	var usageMemory []map[string]string
-	for _, s := range reMem.FindAllStringSubmatch(ssh.Stdout, -1) {
+	for _, s := range reMem.FindAllStringSubmatch(response, -1) {
 		memory := make(map[string]string)
 		for i, v := range s[1:] {
 			memory[keys[i]] = v
@@ -281,29 +460,35 @@ func (asa *CiscoASA) CheckStatus(host string, username string, password string,
 	if message == "" {
 		message = "Everything is Ok"
 	}
-	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, err
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
 }
 
+// CheckVPNUsers check number of Cisco ASA remote access VPN users
 func (asa *CiscoASA) CheckVPNUsers(host string, username string, password string, identity string, port int, critical string, warning string) (ict.Icinga, error) {
 
-	var ssh *ict.SSHTools
-
-	var reUsers = regexp.MustCompile(`(?mi)^remote access VPN user.*\'(?P<username>.*)\'.*$`)
-
-	var warningTH Threshold
-	var criticalTH Threshold
-
-	// Opening a ssh session to the cisco ASA
-	ssh, err = ict.NewSSHTools(host, username, password, identity, port)
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show uauth | include remote access VPN user\n"})
 	if err != nil {
 		return ict.Icinga{}, err
 	}
 
-	// Sending commands to the Cisco ASA and getting returned data
-	err = ssh.SendSSHhasPTY([]string{"enable\n\n", "terminal pager 0\n", "show uauth | include remote access VPN user\n"}, `(?i)^(.*\>.?)|(.*\#.?)|(Password:.?)$`)
-	if err != nil {
+	return asa.ParseVPNUsers(stdout, critical, warning)
+}
+
+// ParseVPNUsers count remote access VPN users and return Icinga result depending critical and warning thresholds,
+// output is empty without users so an error is returned if a line is not a VPN user
+func (asa *CiscoASA) ParseVPNUsers(response string, critical string, warning string) (ict.Icinga, error) {
+
+	var reUsers = regexp.MustCompile(`(?mi)^remote access VPN user.*\'(?P<username>.*)\'.*$`)
+
+	command := "show uauth | include remote access VPN user"
+	if err := checkOutput(response, []string{command}); err != nil {
 		return ict.Icinga{}, err
 	}
+	for _, line := range commandOutput(response, command) {
+		if strings.TrimSpace(line) != "" && !reUsers.MatchString(line) {
+			return ict.Icinga{}, fmt.Errorf("%w, unexpected line in %s => %s", errParse, command, strings.TrimSpace(line))
+		}
+	}
 
 	//
 	// Parsing returned data
@@ -311,7 +496,7 @@ func (asa *CiscoASA) CheckVPNUsers(host string, username string, password string
 	// Creating map for users information information
 	keys := reUsers.SubexpNames()[1:]
 	var users []map[string]string
-	for _, s := range reUsers.FindAllStringSubmatch(ssh.Stdout, -1) {
+	for _, s := range reUsers.FindAllStringSubmatch(response, -1) {
 		user := make(map[string]string)
 		for i, v := range s[1:] {
 			user[keys[i]] = v
@@ -319,6 +504,22 @@ func (asa *CiscoASA) CheckVPNUsers(host string, username string, password string
 		users = append(users, user)
 	}
 
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for idx, user := range users {
+			log.Printf("%d - %s", idx, user["username"])
+		}
+	}
+
+	return vpnUsersResult(len(users), critical, warning), nil
+}
+
+// vpnUsersResult return the state of users VPN remote connected users against critical and warning thresholds
+func vpnUsersResult(users int, critical string, warning string) ict.Icinga {
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
 	// Set exit condition depending status of all probes
 	var condition = ict.OkExit
 	var message = ""
@@ -329,39 +530,44 @@ func (asa *CiscoASA) CheckVPNUsers(host string, username string, password string
 	errWarning := json.Unmarshal([]byte(warning), &warningTH)
 
 	if errWarning == nil && warningTH.UsersVPN > 0 {
-		if len(users) > warningTH.UsersVPN {
+		if users > warningTH.UsersVPN {
 			condition = ict.WarExit
-			message = fmt.Sprintf("%d VPN remote connected users > %d", len(users), warningTH.UsersVPN)
+			message = fmt.Sprintf("%d VPN remote connected users > %d", users, warningTH.UsersVPN)
 		}
 	}
 
 	if errCritical == nil && criticalTH.UsersVPN > 0 {
-		if len(users) > criticalTH.UsersVPN {
+		if users > criticalTH.UsersVPN {
 			condition = ict.CriExit
-			message = fmt.Sprintf("%d VPN remote connected users > %d", len(users), criticalTH.UsersVPN)
+			message = fmt.Sprintf("%d VPN remote connected users > %d", users, criticalTH.UsersVPN)
 		}
 	}
 
 	// Setting CPU usage metrics
-	metrics += fmt.Sprintf("'Active users'=%d ", len(users))
-
-	// Print log values if program is called in Test mode
-	if os.Getenv("VERBOSE") == "TRUE" {
-		for idx, user := range users {
-			log.Printf("%d - %s", idx, user["username"])
-		}
-	}
+	metrics += fmt.Sprintf("'Active users'=%d ", users)
 
 	if message == "" {
-		message = fmt.Sprintf("%d VPN remote connected users", len(users))
+		message = fmt.Sprintf("%d VPN remote connected users", users)
 	}
-	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, err
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}
 }
 
+// CheckFailover check Cisco ASA failover state
 func (asa *CiscoASA) CheckFailover(host string, username string, password string, identity string, port int, critical string, warning string) (ict.Icinga, error) {
 
-	var ssh *ict.SSHTools
-	var reFailoverOn = regexp.MustCompile(`(?mi)^Failover (?P<status>.*)\s*$`)
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show failover\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseFailover(stdout, critical, warning)
+}
+
+// ParseFailover parse failover state and return Icinga result depending critical and warning thresholds on active unit time,
+// an error is returned if failover status is not found
+func (asa *CiscoASA) ParseFailover(response string, critical string, warning string) (ict.Icinga, error) {
+
+	var reFailoverOn = regexp.MustCompile(`(?mi)^Failover (?P<status>On|Off)\s*$`)
 	var reFailoverLink = regexp.MustCompile(`(?mi)^Failover LAN Interface:.*\((?P<failover_state>.*)\)\s*$`)
 	var reLastFailover = regexp.MustCompile(`(?mi)^Last Failover at:\s(?P<time>\d{2}:\d{2}:\d{2}\s[A-Z]{1,3}[T]\s\w{3}\s\d{1,2}\s\d{4})\s*$`)
 	var reThisHost = regexp.MustCompile(`(?mi)^\s*This host:\s(?P<host>\w*)\s*\-\s*(?P<state>.*)\s\s*$`)
@@ -376,15 +582,7 @@ func (asa *CiscoASA) CheckFailover(host string, username string, password string
 	var message = ""
 	var metrics = ""
 
-	// Opening a ssh session to the cisco ASA
-	ssh, err = ict.NewSSHTools(host, username, password, identity, port)
-	if err != nil {
-		return ict.Icinga{}, err
-	}
-
-	// Sending commands to the Cisco ASA and getting returned data
-	err = ssh.SendSSHhasPTY([]string{"enable\n\n", "terminal pager 0\n", "show failover\n"}, `(?i)^(.*\>.?)|(.*\#.?)|(Password:.?)$`)
-	if err != nil {
+	if err := checkOutput(response, []string{"show failover"}, section{"show failover", "Failover status", reFailoverOn}); err != nil {
 		return ict.Icinga{}, err
 	}
 
@@ -393,23 +591,18 @@ func (asa *CiscoASA) CheckFailover(host string, username string, password string
 	//
 
 	//Checking if Failover is On (First line of response)
-	failoverOn := reFailoverOn.FindStringSubmatch(ssh.Stdout)
-	if failoverOn != nil {
-		if strings.ToUpper(strings.TrimSpace(failoverOn[1])) != "ON" {
-			condition = ict.CriExit
-			message = "Failover status not On"
-		}
-	} else {
+	failoverOn := reFailoverOn.FindStringSubmatch(response)
+	if strings.ToUpper(strings.TrimSpace(failoverOn[1])) != "ON" {
 		condition = ict.CriExit
-		message = "Failover status not found"
+		message = "Failover status not On"
 	}
-	// If failover is not On or no information are returned exiting with Critical status
+	// If failover is not On exiting with Critical status
 	if condition == ict.CriExit {
-		return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, err
+		return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
 	}
 
 	//Checking if Failover link is Up
-	failoverLink := reFailoverLink.FindStringSubmatch(ssh.Stdout)
+	failoverLink := reFailoverLink.FindStringSubmatch(response)
 	if failoverLink != nil {
 		if strings.ToUpper(strings.TrimSpace(failoverLink[1])) != "UP" {
 			condition = ict.CriExit
@@ -418,11 +611,11 @@ func (asa *CiscoASA) CheckFailover(host string, username string, password string
 	} else {
 		condition = ict.CriExit
 		message = "Failover link information not found"
-		return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, err
+		return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
 	}
 
 	// Checking last failover and parsing datetime
-	lastFailover := reLastFailover.FindStringSubmatch(ssh.Stdout)
+	lastFailover := reLastFailover.FindStringSubmatch(response)
 	if lastFailover != nil {
 		lastFailoverTime, err := time.Parse("15:04:05 MST Jan 2 2006", lastFailover[1])
 		if err == nil {
@@ -437,9 +630,14 @@ func (asa *CiscoASA) CheckFailover(host string, username string, password string
 	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
 	errWarning := json.Unmarshal([]byte(warning), &warningTH)
 
-	thisHost := reThisHost.FindStringSubmatch(ssh.Stdout)
-	otherHost := reOtherHost.FindStringSubmatch(ssh.Stdout)
-	activeTime := reActiveTime.FindAllStringSubmatch(ssh.Stdout, 2)
+	thisHost := reThisHost.FindStringSubmatch(response)
+	otherHost := reOtherHost.FindStringSubmatch(response)
+	activeTime := reActiveTime.FindAllStringSubmatch(response, 2)
+
+	// Active time of both hosts is needed to evaluate the active unit
+	if thisHost != nil && otherHost != nil && len(activeTime) < 2 {
+		return ict.Icinga{}, fmt.Errorf("%w, Active time of both hosts not found in show failover => %s", errParse, strings.TrimSpace(thisHost[0]))
+	}
 
 	// Testing which host is active and active duration if duration is lower than threshold raising Warning or Critical exit condition
 	if thisHost != nil && otherHost != nil {
@@ -504,5 +702,5 @@ func (asa *CiscoASA) CheckFailover(host string, username string, password string
 		message += fmt.Sprintf("%s host is %s, %s host is %s", thisHost[1], thisHost[2], otherHost[1], otherHost[2])
 	}
 
-	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, err
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
 }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const StatusResponse = `asa# show environment
+
+Cooling Fans:
+-----------------------------------
+  Power Supplies:
+  --------------------------------
+  Left Slot (PS0):     8704 RPM - OK (Power Supply Fan)
+
+Temperature:
+-----------------------------------
+  Processors:
+  --------------------------------
+  Processor 0:          45.0 C - OK (CPU1 Core Temperature)
+
+  Chassis:
+  --------------------------------
+  Ambient 1:            28.0 C - OK (Chassis Front Temperature)
+asa# show cpu
+CPU utilization for 5 seconds = 12%; 1 minute: 10%; 5 minutes: 9%
+asa# show mem
+Free memory:        5612347392 bytes (65%)
+Used memory:        2977513472 bytes (35%)
+-------------     ------------------
+Total memory:       8589860864 bytes (100%)
+asa# `
+
+func TestCiscoASA_ParseStatus(t *testing.T) {
+	icinga, err := asa.ParseStatus(StatusResponse, `{"cpu":[90,70,50],"memory":20}`, `{"cpu":[70,50,30],"memory":30}`)
+	if err != nil {
+		t.Fatalf("Error ParseStatus: %s", err)
+	}
+	if icinga.Exit != ict.OkExit || !strings.Contains(icinga.Metric, "'CPU usage [5s]'=12%") || !strings.Contains(icinga.Metric, "'Free memory'=65%") {
+		t.Errorf("Error unexpected result %v", icinga)
+	}
+
+	// Memory usage not found
+	response := strings.Replace(StatusResponse, "Free memory:", "Free mem:", 1)
+	if _, err = asa.ParseStatus(response, `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "Free memory not found in show mem => Free mem:        5612347392 bytes (65%)") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+
+	// CPU usage command rejected
+	response = strings.Replace(StatusResponse, "CPU utilization for 5 seconds = 12%; 1 minute: 10%; 5 minutes: 9%", "Command authorization failed", 1)
+	if _, err = asa.ParseStatus(response, `{}`, `{}`); !errors.Is(err, errUnsupported) || !strings.HasSuffix(err.Error(), "show cpu => Command authorization failed") {
+		t.Errorf("Error want %s got %v", errUnsupported, err)
+	}
+}
+
+func TestCiscoASA_ParseVPNUsers(t *testing.T) {
+	icinga, err := asa.ParseVPNUsers(VPNUsersResponse, `{"users_vpn":5}`, `{"users_vpn":1}`)
+	if err != nil {
+		t.Fatalf("Error ParseVPNUsers: %s", err)
+	}
+	if icinga.Exit != ict.WarExit || icinga.Message != "2 VPN remote connected users > 1" {
+		t.Errorf("Error unexpected result %v", icinga)
+	}
+
+	// No users
+	if icinga, err = asa.ParseVPNUsers("asa# show uauth | include remote access VPN user\nasa# ", `{}`, `{}`); err != nil || icinga.Message != "0 VPN remote connected users" {
+		t.Errorf("Error unexpected result %v (%v)", icinga, err)
+	}
+
+	// ASA error instead of users
+	response := "asa# show uauth | include remote access VPN user\n                  ^\nERROR: % Invalid input detected at '^' marker.\nasa# "
+	if _, err = asa.ParseVPNUsers(response, `{}`, `{}`); !errors.Is(err, errUnsupported) {
+		t.Errorf("Error want %s got %v", errUnsupported, err)
+	}
+
+	// Unexpected output
+	response = "asa# show uauth | include remote access VPN user\nVPN user alice at 10.10.10.1\nasa# "
+	if _, err = asa.ParseVPNUsers(response, `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "=> VPN user alice at 10.10.10.1") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
+
+func TestCiscoASA_ParseFailover(t *testing.T) {
+	if _, err := asa.ParseFailover("asa# show failover\nasa# ", `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "=> empty output") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+
+	icinga, err := asa.ParseFailover("asa# show failover\nFailover Off\nasa# ", `{}`, `{}`)
+	if err != nil || icinga.Exit != ict.CriExit || icinga.Message != "Failover status not On" {
+		t.Errorf("Error unexpected result %v (%v)", icinga, err)
+	}
+
+	// Failover LAN interface line is not the failover status
+	response := "asa# show failover\nFailover LAN Interface: folink GigabitEthernet0/7 (up)\nasa# "
+	if _, err = asa.ParseFailover(response, `{}`, `{}`); !errors.Is(err, errParse) {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+
+	// Hosts without active time
+	response = regexp.MustCompile(`(?m)^\s*Active time:.*\n`).ReplaceAllString(FailoverResponse, "")
+	if _, err = asa.ParseFailover(response, `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "=> This host: Primary - Active") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
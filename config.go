@@ -0,0 +1,127 @@
+// This file content implementation of methods to detect CISCO ASA configuration changes
+// against a locally stored baseline
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// defaultConfigIgnore are volatile lines of running configuration never compared
+var defaultConfigIgnore = []string{
+	`^:`,                // Comments (Saved, Written by, timestamps)
+	`^Cryptochecksum:`,  // Checksum of the configuration
+	`^ntp clock-period`, // Automatically adjusted by the ASA
+}
+
+// maxDiffLines limit the number of diff lines returned in long output
+const maxDiffLines = 100
+
+// CheckConfig compare Cisco ASA running configuration with the baseline stored in stateDir
+func (asa *CiscoASA) CheckConfig(host string, username string, password string, identity string, port int, stateDir string, ignore []string, accept bool) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show running-config\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.CompareConfig(commandOutput(stdout, "show running-config"), stateFile(stateDir, host, "running-config"), ignore, accept)
+}
+
+// CompareConfig normalize configuration lines and compare them with baseline file, baseline is created
+// if not exists or replaced if accept is true
+func (asa *CiscoASA) CompareConfig(config []string, baselineFile string, ignore []string, accept bool) (ict.Icinga, error) {
+
+	var reIgnore []*regexp.Regexp
+	for _, pattern := range append(defaultConfigIgnore, ignore...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return ict.Icinga{}, fmt.Errorf("CompareConfig, %w, invalid ignore pattern %s: %s", errConfig, pattern, err)
+		}
+		reIgnore = append(reIgnore, re)
+	}
+
+	current := normalizeConfig(config, reIgnore)
+	if len(current) == 0 {
+		return ict.Icinga{Message: "Running configuration not found", Exit: ict.UnkExit}, nil
+	}
+
+	baselineData, err := readState(baselineFile)
+	if err != nil && !os.IsNotExist(err) {
+		return ict.Icinga{}, fmt.Errorf("CompareConfig, %w, unable to read baseline: %s", errConfig, err)
+	}
+
+	// Creating or replacing baseline
+	if os.IsNotExist(err) || accept {
+		if err := writeState(baselineFile, []byte(strings.Join(current, "\n")+"\n")); err != nil {
+			return ict.Icinga{}, err
+		}
+		action := "created"
+		if accept {
+			action = "accepted"
+		}
+		return ict.Icinga{Message: fmt.Sprintf("Configuration baseline %s (%d lines)", action, len(current)), Exit: ict.OkExit, Metric: "'Changed lines'=0 "}, nil
+	}
+
+	// Ignore patterns may have changed since baseline was stored
+	baseline := normalizeConfig(strings.Split(string(baselineData), "\n"), reIgnore)
+	ops := diffLines(baseline, current)
+
+	added, removed := 0, 0
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	metrics := fmt.Sprintf("'Changed lines'=%d ", added+removed)
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		log.Printf("Baseline %s %d lines, running configuration %d lines, %d added, %d removed\n", baselineFile, len(baseline), len(current), added, removed)
+	}
+
+	if added+removed == 0 {
+		return ict.Icinga{Message: fmt.Sprintf("Configuration unchanged (%d lines)", len(current)), Exit: ict.OkExit, Metric: metrics}, nil
+	}
+
+	// Limiting diff size in long output
+	diff := strings.Split(strings.TrimSuffix(unifiedDiff(ops, 2), "\n"), "\n")
+	if len(diff) > maxDiffLines {
+		diff = append(diff[:maxDiffLines], fmt.Sprintf("... %d more lines", len(diff)-maxDiffLines))
+	}
+
+	message := fmt.Sprintf("Configuration changed since baseline: %d lines added, %d lines removed\n%s", added, removed, strings.Join(diff, "\n"))
+	return ict.Icinga{Message: message, Exit: ict.WarExit, Metric: metrics}, nil
+}
+
+// normalizeConfig remove ignored and empty lines and mask secrets so they are never stored in baseline
+func normalizeConfig(config []string, reIgnore []*regexp.Regexp) []string {
+	var reSecret = regexp.MustCompile(`(?i)\b(password|passwd|secret|key|pre-shared-key|authentication-key|key-string)\s+\S+`)
+	var normalized []string
+
+	for _, line := range config {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		ignored := false
+		for _, re := range reIgnore {
+			if re.MatchString(line) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			normalized = append(normalized, reSecret.ReplaceAllString(line, "$1 *****"))
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	RunningConfigResponse = `asa# show running-config
+: Saved
+
+:
+: Serial Number: FCH1234ABCD
+: Hardware:   ASA5515, 8192 MB RAM, CPU Clarkdale 3058 MHz, 1 CPU (4 cores)
+: Written by enable_15 at 14:05:22.102 UTC Tue Mar 15 2016
+!
+ASA Version 9.8(4)20
+!
+hostname asa
+enable password $sha512$5000$abcdef== pbkdf2
+names
+!
+interface GigabitEthernet0/0
+ nameif outside
+ security-level 0
+ ip address 198.51.100.2 255.255.255.0
+!
+interface GigabitEthernet0/1
+ nameif inside
+ security-level 100
+ ip address 10.1.1.1 255.255.255.0
+!
+access-list outside_in extended permit tcp any host 10.1.1.10 eq https
+access-list outside_in extended deny ip any any log
+ntp clock-period 12345
+username icinga password $sha512$5000$xyz== pbkdf2 privilege 15
+Cryptochecksum:0123456789abcdef0123456789abcdef
+: end
+asa# `
+)
+
+func TestCommandOutput(t *testing.T) {
+	output := commandOutput(strings.Replace(RunningConfigResponse, "\n", "\r\n", -1), "show running-config")
+	if len(output) != 29 {
+		t.Errorf("Error want 29 lines got %d", len(output))
+	}
+	if output[0] != ": Saved" || output[len(output)-1] != ": end" {
+		t.Errorf("Error unexpected output boundaries %q - %q", output[0], output[len(output)-1])
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	a := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}
+	b := []string{"a", "b", "x", "d", "e", "f", "g", "h", "i", "j"}
+	want := "@@ -1,5 +1,5 @@\n a\n b\n-c\n+x\n d\n e\n@@ -8,2 +8,3 @@\n h\n i\n+j\n"
+	if diff := unifiedDiff(diffLines(a, b), 2); diff != want {
+		t.Errorf("Error want diff\n%s\ngot\n%s", want, diff)
+	}
+	if diff := unifiedDiff(diffLines(a, a), 2); diff != "" {
+		t.Errorf("Error want no diff got\n%s", diff)
+	}
+}
+
+func TestCiscoASA_CompareConfig(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	baselineFile := filepath.Join(stateDir, "asa_running-config")
+
+	config := commandOutput(RunningConfigResponse, "show running-config")
+	icinga, err := asa.CompareConfig(config, baselineFile, nil, false)
+	if err != nil || icinga.Exit != ict.OkExit || !strings.HasPrefix(icinga.Message, "Configuration baseline created") {
+		t.Fatalf("Error creating baseline: %s %s", icinga, err)
+	}
+	baseline, _ := ioutil.ReadFile(baselineFile)
+	if strings.Contains(string(baseline), "$sha512$") || strings.Contains(string(baseline), "Cryptochecksum") {
+		t.Errorf("Error secrets or volatile lines stored in baseline:\n%s", baseline)
+	}
+
+	// Volatile lines changes are not reported
+	changed := strings.Replace(RunningConfigResponse, "ntp clock-period 12345", "ntp clock-period 12346", 1)
+	changed = strings.Replace(changed, "Cryptochecksum:0123", "Cryptochecksum:4567", 1)
+	icinga, _ = asa.CompareConfig(commandOutput(changed, "show running-config"), baselineFile, nil, false)
+	if icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.OkExit, icinga.Exit, icinga)
+	}
+
+	changed = strings.Replace(changed, "deny ip any any log", "permit ip any any", 1)
+	icinga, _ = asa.CompareConfig(commandOutput(changed, "show running-config"), baselineFile, nil, false)
+	if icinga.Exit != ict.WarExit || !strings.Contains(icinga.Message, "-access-list outside_in extended deny ip any any log\n+access-list outside_in extended permit ip any any") {
+		t.Errorf("Error change not reported: %s", icinga)
+	}
+
+	icinga, _ = asa.CompareConfig(commandOutput(changed, "show running-config"), baselineFile, []string{`^access-list`}, false)
+	if icinga.Exit != ict.OkExit {
+		t.Errorf("Error ignored change reported: %s", icinga)
+	}
+
+	asa.CompareConfig(commandOutput(changed, "show running-config"), baselineFile, nil, true)
+	icinga, _ = asa.CompareConfig(commandOutput(changed, "show running-config"), baselineFile, nil, false)
+	if icinga.Exit != ict.OkExit {
+		t.Errorf("Error accepted baseline reported as changed: %s", icinga)
+	}
+}
+
+func TestCheck_Config(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckConfig(params.host, params.username, params.password, params.identity, params.port, params.stateDir, params.ignore, params.accept)
+	if err != nil {
+		t.Errorf("Error CheckConfig: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,110 @@
+// This file content functions reading passwords from files so they never appear on the command line,
+// secret files readable by everyone are rejected
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Credentials are the passwords of a host found in a credentials file
+type Credentials struct {
+	Login    string
+	Password string
+	Enable   string
+	Identity string
+}
+
+// checkPermissions return an error if file is readable or writable by everyone
+// (permissions are not checked on Windows)
+func checkPermissions(file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("checkPermissions, %w, unable to read secret file: %s", errConfig, err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0007 != 0 {
+		return fmt.Errorf("checkPermissions, %w, secret file %s is accessible by everyone (%s), remove others permissions", errConfig, file, info.Mode().Perm())
+	}
+	return nil
+}
+
+// readSecret return the first line of file
+func readSecret(file string) (string, error) {
+	if err := checkPermissions(file); err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("readSecret, %w, unable to read secret file: %s", errConfig, err)
+	}
+	return strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r"), nil
+}
+
+// lookupCredentials search host in a netrc like credentials file, tokens are separated by blanks or newlines:
+//
+//	machine <host> login <username> password <password> enable <enable password>
+//	machine <jump host> login <username> identity <private key file>
+//	default login <username> password <password>
+//
+// the first machine entry matching host (and username if entry has a login) is returned, default entry
+// is used if no machine match, nil is returned if nothing match
+func lookupCredentials(file string, host string, username string) (*Credentials, error) {
+	if err := checkPermissions(file); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("lookupCredentials, %w, unable to read credentials file: %s", errConfig, err)
+	}
+
+	// Lines starting with # are comments
+	var tokens []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			tokens = append(tokens, strings.Fields(line)...)
+		}
+	}
+
+	var entries []map[string]string
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine":
+			if i+1 >= len(tokens) {
+				return nil, fmt.Errorf("lookupCredentials, %w, machine without name in %s", errConfig, file)
+			}
+			entries = append(entries, map[string]string{"machine": tokens[i+1]})
+			i++
+		case "default":
+			entries = append(entries, map[string]string{"default": "true"})
+		case "login", "password", "enable", "identity":
+			if len(entries) == 0 || i+1 >= len(tokens) {
+				return nil, fmt.Errorf("lookupCredentials, %w, invalid %s token in %s", errConfig, tokens[i], file)
+			}
+			entries[len(entries)-1][tokens[i]] = tokens[i+1]
+			i++
+		default:
+			return nil, fmt.Errorf("lookupCredentials, %w, unknown token %s in %s", errConfig, tokens[i], file)
+		}
+	}
+
+	var found map[string]string
+	for _, entry := range entries {
+		if entry["login"] != "" && username != "" && entry["login"] != username {
+			continue
+		}
+		if strings.EqualFold(entry["machine"], host) {
+			found = entry
+			break
+		}
+		if entry["default"] != "" && found == nil {
+			found = entry
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	return &Credentials{Login: found["login"], Password: found["password"], Enable: found["enable"], Identity: found["identity"]}, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const (
+	CredentialsFile = `# Firewalls of the datacenter
+machine asa1.example.com login icinga password secret1 enable enable1
+machine asa2.example.com
+    login icinga
+    password secret2
+machine bastion.example.com login jump identity /etc/icinga2/bastion_key
+default login icinga password secret0
+`
+)
+
+func TestReadSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "secret")
+
+	if err = ioutil.WriteFile(file, []byte("p@ss word#1\r\nignored\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := readSecret(file); err != nil || secret != "p@ss word#1" {
+		t.Errorf("Error want secret %q got %q (%v)", "p@ss word#1", secret, err)
+	}
+
+	// Secret file readable by everyone is rejected
+	if runtime.GOOS != "windows" {
+		if err = os.Chmod(file, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = readSecret(file); err == nil {
+			t.Errorf("Error secret file readable by everyone accepted")
+		}
+	}
+
+	if _, err = readSecret(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Error missing secret file accepted")
+	}
+}
+
+func TestLookupCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "credentials")
+	if err = ioutil.WriteFile(file, []byte(CredentialsFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host     string
+		username string
+		want     Credentials
+	}{
+		{"asa1.example.com", "icinga", Credentials{"icinga", "secret1", "enable1", ""}},
+		{"ASA2.example.com", "icinga", Credentials{"icinga", "secret2", "", ""}},
+		{"asa3.example.com", "icinga", Credentials{"icinga", "secret0", "", ""}},
+		{"bastion.example.com", "jump", Credentials{"jump", "", "", "/etc/icinga2/bastion_key"}},
+	}
+	for _, test := range tests {
+		credentials, err := lookupCredentials(file, test.host, test.username)
+		if err != nil || credentials == nil || *credentials != test.want {
+			t.Errorf("Error %s want %v got %v (%v)", test.host, test.want, credentials, err)
+		}
+	}
+
+	// Login of entries must match username
+	if credentials, err := lookupCredentials(file, "asa1.example.com", "admin"); err != nil || credentials != nil {
+		t.Errorf("Error want no credentials got %v (%v)", credentials, err)
+	}
+
+	if err = ioutil.WriteFile(file, []byte("machine asa1 login icinga passwd secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = lookupCredentials(file, "asa1", "icinga"); err == nil {
+		t.Errorf("Error invalid credentials file accepted")
+	}
+}
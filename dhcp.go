@@ -0,0 +1,132 @@
+// This file content implementation of methods to check CISCO ASA DHCP server
+// pools utilization
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckDHCP check Cisco ASA DHCP server pools utilization
+func (asa *CiscoASA) CheckDHCP(host string, username string, password string, identity string, port int, critical string, warning string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show running-config dhcpd\n", "show dhcpd binding\n", "show dhcpd statistics\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseDHCP(stdout, critical, warning)
+}
+
+// ParseDHCP parse DHCP pools and bindings and return Icinga result depending critical and warning thresholds
+func (asa *CiscoASA) ParseDHCP(response string, critical string, warning string) (ict.Icinga, error) {
+
+	var rePool = regexp.MustCompile(`(?mi)^\s*dhcpd address (?P<first>\d{1,3}(?:\.\d{1,3}){3})-(?P<last>\d{1,3}(?:\.\d{1,3}){3}) (?P<interface>\S+)\s*$`)
+	var reBinding = regexp.MustCompile(`(?mi)^\s*(?P<address>\d{1,3}(?:\.\d{1,3}){3})\s+(?P<client>\S+)\s+.*$`)
+	var reExpired = regexp.MustCompile(`(?mi)^\s*Expired bindings\s+(?P<count>\d+)\s*$`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	commands := []string{"show running-config dhcpd", "show dhcpd binding", "show dhcpd statistics"}
+	if err := checkOutput(response, commands); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for pools by interface
+	keys := rePool.SubexpNames()[1:]
+	var pools []map[string]string
+	for _, s := range rePool.FindAllStringSubmatch(response, -1) {
+		pool := make(map[string]string)
+		for i, v := range s[1:] {
+			pool[keys[i]] = v
+		}
+		pools = append(pools, pool)
+	}
+	if len(pools) == 0 {
+		return ict.Icinga{}, missingError(response, commands[0], "DHCP address pool")
+	}
+
+	// Bindings are only searched in show dhcpd binding output
+	var bindings []uint32
+	for _, s := range reBinding.FindAllStringSubmatch(strings.Join(commandOutput(response, "show dhcpd binding"), "\n"), -1) {
+		bindings = append(bindings, ipToUint32(s[1]))
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	for _, pool := range pools {
+		first, last := ipToUint32(pool["first"]), ipToUint32(pool["last"])
+		if last < first {
+			continue
+		}
+		size := int(last - first + 1)
+		leased := 0
+		for _, binding := range bindings {
+			if binding >= first && binding <= last {
+				leased++
+			}
+		}
+		percUsed := leased * 100 / size
+
+		if errCritical == nil && criticalTH.DHCPPool > 0 && percUsed >= criticalTH.DHCPPool {
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("%s pool %d%% used >= %d%%", pool["interface"], percUsed, criticalTH.DHCPPool))
+		} else if errWarning == nil && warningTH.DHCPPool > 0 && percUsed >= warningTH.DHCPPool {
+			if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("%s pool %d%% used >= %d%%", pool["interface"], percUsed, warningTH.DHCPPool))
+		}
+
+		// Setting pool metrics
+		metrics += fmt.Sprintf("'%s leased'=%d;;;0;%d ", pool["interface"], leased, size)
+		metrics += fmt.Sprintf("'%s used'=%d%% ", pool["interface"], percUsed)
+		details += fmt.Sprintf("\n%s %s-%s: %d leased, %d available", pool["interface"], pool["first"], pool["last"], leased, size-leased)
+	}
+
+	if expired := reExpired.FindStringSubmatch(response); expired != nil {
+		metrics += fmt.Sprintf("'Expired bindings'=%sc ", expired[1])
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, pool := range pools {
+			log.Printf("Pool %s %s-%s\n", pool["interface"], pool["first"], pool["last"])
+		}
+		log.Printf("%d bindings\n", len(bindings))
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("%d DHCP pools Ok, %d bindings", len(pools), len(bindings))
+	}
+	return ict.Icinga{Message: message + details, Exit: condition, Metric: metrics}, nil
+}
+
+// ipToUint32 convert an IPv4 address to integer, invalid address is 0
+func ipToUint32(address string) uint32 {
+	ip := net.ParseIP(address).To4()
+	if ip == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(ip)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	DHCPResponse = `asa# show running-config dhcpd
+dhcpd dns 10.1.1.53
+dhcpd address 192.168.10.100-192.168.10.103 inside
+dhcpd address 192.168.20.10-192.168.20.209 guest
+dhcpd enable inside
+dhcpd enable guest
+asa# show dhcpd binding
+
+IP address      Client Identifier        Lease expiration        Type
+192.168.10.100  0100.a0c9.868e.43             84985 seconds    Automatic
+192.168.10.101  0100.a0c9.868e.44             80000 seconds    Automatic
+192.168.10.102  0100.a0c9.868e.45             70000 seconds    Automatic
+192.168.20.10   0100.a0c9.868e.46             86000 seconds    Automatic
+asa# show dhcpd statistics
+
+DHCP UDP Unreachable Errors: 0
+DHCP Other UDP Errors: 0
+
+Address pools        2
+Automatic bindings   4
+Expired bindings     17
+Malformed messages   0
+asa# `
+)
+
+func TestCiscoASA_ParseDHCP(t *testing.T) {
+	icinga, err := asa.ParseDHCP(DHCPResponse, `{"dhcp_pool":90}`, `{"dhcp_pool":70}`)
+	if err != nil {
+		t.Fatalf("Error ParseDHCP: %s", err)
+	}
+	if icinga.Exit != ict.WarExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+	want := "inside pool 75% used >= 70%\ninside 192.168.10.100-192.168.10.103: 3 leased, 1 available\nguest 192.168.20.10-192.168.20.209: 1 leased, 199 available"
+	if icinga.Message != want {
+		t.Errorf("Error want message\n%s\ngot\n%s", want, icinga.Message)
+	}
+	if !strings.HasPrefix(icinga.Metric, "'inside leased'=3;;;0;4 'inside used'=75% 'guest leased'=1;;;0;200") || !strings.HasSuffix(icinga.Metric, "'Expired bindings'=17c ") {
+		t.Errorf("Error unexpected metrics %s", icinga.Metric)
+	}
+
+	// Unrecognized output is an error with the offending line
+	if _, err = asa.ParseDHCP("asa# show running-config dhcpd\ndhcpd enable inside\nasa# ", `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "DHCP address pool not found in show running-config dhcpd => dhcpd enable inside") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
+
+func TestCheck_DHCP(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckDHCP(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+	if err != nil {
+		t.Errorf("Error CheckDHCP: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,181 @@
+// This file content a minimal line based diff (Myers algorithm) used to report
+// configuration changes as unified diff
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffEdits limit the edit distance computed, above this limit files are considered fully replaced
+const maxDiffEdits = 1000
+
+type diffOp struct {
+	kind byte // ' ' unchanged line, '-' removed line, '+' added line
+	text string
+}
+
+// diffLines return the edit script to transform a into b
+func diffLines(a []string, b []string) []diffOp {
+	var ops []diffOp
+
+	// Common prefix and suffix are not given to Myers algorithm
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// myers compute the shortest edit script between a and b
+func myers(a []string, b []string) []diffOp {
+	var ops []diffOp
+	var trace [][]int
+
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+
+	found := false
+	for d := 0; d <= n+m && d <= maxDiffEdits && !found; d++ {
+		// Keeping only the useful part of v for backtracking
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// Too many differences, everything is replaced
+	if !found {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// Backtracking from the end of both files
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		tv := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && tv[k-1+d+1] < tv[k+1+d+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := tv[prevK+d+1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', b[y-1]})
+				y--
+			} else {
+				ops = append(ops, diffOp{'-', a[x-1]})
+				x--
+			}
+		}
+	}
+
+	// Edit script was built backward
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// unifiedDiff format edit script as unified diff hunks with context lines around changes
+func unifiedDiff(ops []diffOp, context int) string {
+	var out strings.Builder
+
+	for start := 0; start < len(ops); {
+		// Searching next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extending hunk while changes are closer than two contexts
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i
+			} else if i-end > 2*context {
+				break
+			}
+		}
+
+		first := start - context
+		if first < 0 {
+			first = 0
+		}
+		last := end + context
+		if last > len(ops)-1 {
+			last = len(ops) - 1
+		}
+
+		// Computing line numbers of the hunk
+		aLine, bLine := 1, 1
+		for _, op := range ops[:first] {
+			if op.kind != '+' {
+				aLine++
+			}
+			if op.kind != '-' {
+				bLine++
+			}
+		}
+		aCount, bCount := 0, 0
+		var lines []string
+		for _, op := range ops[first : last+1] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+			lines = append(lines, string(op.kind)+op.text)
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+		out.WriteString(strings.Join(lines, "\n") + "\n")
+		start = last + 1
+	}
+	return out.String()
+}
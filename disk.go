@@ -0,0 +1,131 @@
+// This file content implementation of methods to check CISCO ASA flash and disk
+// filesystems utilization
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckDisk check Cisco ASA filesystems free space and crash files
+func (asa *CiscoASA) CheckDisk(host string, username string, password string, identity string, port int, critical string, warning string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"dir /recursive all-filesystems\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseDisk(stdout, critical, warning)
+}
+
+// ParseDisk parse the output of dir command and return Icinga result depending critical and warning thresholds
+func (asa *CiscoASA) ParseDisk(response string, critical string, warning string) (ict.Icinga, error) {
+
+	var reDirectory = regexp.MustCompile(`(?i)^\s*Directory of (?P<filesystem>[\w\-]+):/.*$`)
+	var reTotal = regexp.MustCompile(`(?i)^\s*(?P<total>\d+) bytes total \((?P<free>\d+) bytes free.*\)\s*$`)
+	var reFile = regexp.MustCompile(`(?i)^\s*\d+\s+-[-rwx]{3}\s+\d+\s+.*\d{4}\s+(?P<name>\S+)\s*$`)
+	var reCrashFile = regexp.MustCompile(`(?i)^(crashinfo|core[._])`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+
+	commands := []string{"dir /recursive all-filesystems"}
+	if err := checkOutput(response, commands); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for filesystems totals, filesystem is given by the last directory listed
+	var filesystems []map[string]string
+	var crashFiles []string
+	filesystem := ""
+	for _, line := range strings.Split(strings.Replace(response, "\r", "", -1), "\n") {
+		if m := reDirectory.FindStringSubmatch(line); m != nil {
+			filesystem = m[1]
+		} else if m := reTotal.FindStringSubmatch(line); m != nil && filesystem != "" {
+			if len(filesystems) > 0 && filesystems[len(filesystems)-1]["filesystem"] == filesystem {
+				filesystems = filesystems[:len(filesystems)-1]
+			}
+			filesystems = append(filesystems, map[string]string{"filesystem": filesystem, "total": m[1], "free": m[2]})
+		} else if m := reFile.FindStringSubmatch(line); m != nil && reCrashFile.MatchString(m[1]) {
+			crashFiles = append(crashFiles, fmt.Sprintf("%s:%s", filesystem, m[1]))
+		}
+	}
+
+	if len(filesystems) == 0 {
+		return ict.Icinga{}, missingError(response, commands[0], "Filesystem total")
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	checked := 0
+	for _, fs := range filesystems {
+		total, _ := strconv.ParseInt(fs["total"], 10, 64)
+		free, _ := strconv.ParseInt(fs["free"], 10, 64)
+		// Pseudo filesystems like system: have no size
+		if total == 0 {
+			continue
+		}
+		checked++
+		percFree := int(free * 100 / total)
+
+		if errCritical == nil && criticalTH.DiskFree > 0 && percFree < criticalTH.DiskFree {
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("%s: free space %d%% lower than %d%%", fs["filesystem"], percFree, criticalTH.DiskFree))
+		} else if errWarning == nil && warningTH.DiskFree > 0 && percFree < warningTH.DiskFree {
+			if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("%s: free space %d%% lower than %d%%", fs["filesystem"], percFree, warningTH.DiskFree))
+		}
+
+		// Setting filesystem metrics
+		metrics += fmt.Sprintf("'%s: used'=%dB;;;0;%d ", fs["filesystem"], total-free, total)
+		metrics += fmt.Sprintf("'%s: free'=%d%% ", fs["filesystem"], percFree)
+	}
+
+	if errCritical == nil && criticalTH.CoreFiles > 0 && len(crashFiles) > criticalTH.CoreFiles {
+		condition = ict.CriExit
+		message = appendMessage(message, fmt.Sprintf("%d crashinfo/core files > %d", len(crashFiles), criticalTH.CoreFiles))
+	} else if errWarning == nil && warningTH.CoreFiles > 0 && len(crashFiles) > warningTH.CoreFiles {
+		if condition < ict.WarExit {
+			condition = ict.WarExit
+		}
+		message = appendMessage(message, fmt.Sprintf("%d crashinfo/core files > %d", len(crashFiles), warningTH.CoreFiles))
+	}
+	metrics += fmt.Sprintf("'Crash files'=%d ", len(crashFiles))
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, fs := range filesystems {
+			log.Printf("%s: %s bytes total, %s bytes free\n", fs["filesystem"], fs["total"], fs["free"])
+		}
+		for _, file := range crashFiles {
+			log.Printf("Crash file %s\n", file)
+		}
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("%d filesystems free space Ok, %d crashinfo/core files", checked, len(crashFiles))
+	}
+	if len(crashFiles) > 0 {
+		message += "\nCrash files:\n" + strings.Join(crashFiles, "\n")
+	}
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	DirResponse = `asa# dir /recursive all-filesystems
+
+Directory of disk0:/
+
+11     -rwx  37416960     10:43:49 Mar 02 2020  asa984-20-smp-k8.bin
+12     drwx  4096         18:44:32 Jan 14 2020  log
+13     -rwx  26542964     11:03:44 Mar 02 2020  asdm-7121.bin
+31     drwx  4096         18:44:48 Jan 14 2020  coredumpinfo
+45     -rwx  59           07:32:10 Mar 15 2016  crashinfo_20160315_073210_UTC
+
+Directory of disk0:/coredumpinfo/
+
+32     -rwx  59           18:44:48 Jan 14 2020  coredump.cfg
+33     -rwx  101485345    07:32:15 Mar 15 2016  core_lina.2016Mar15_073210.gz
+
+8571076608 bytes total (771396894 bytes free)
+
+Directory of system:/
+
+2      dr-x  0            07:13:05 Mar 14 2016  running-config
+
+0 bytes total (0 bytes free)
+asa# `
+)
+
+func TestCiscoASA_ParseDisk(t *testing.T) {
+	icinga, err := asa.ParseDisk(DirResponse, `{"disk_free":5}`, `{"disk_free":10,"core_files":1}`)
+	if err != nil {
+		t.Fatalf("Error ParseDisk: %s", err)
+	}
+	if icinga.Exit != ict.WarExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+	if !strings.HasPrefix(icinga.Message, "disk0: free space 8% lower than 10%/2 crashinfo/core files > 1\nCrash files:\ndisk0:crashinfo_20160315_073210_UTC\ndisk0:core_lina.2016Mar15_073210.gz") {
+		t.Errorf("Error unexpected message: %s", icinga.Message)
+	}
+	if icinga.Metric != "'disk0: used'=7799679714B;;;0;8571076608 'disk0: free'=8% 'Crash files'=2 " {
+		t.Errorf("Error unexpected metrics: %s", icinga.Metric)
+	}
+
+	// Unrecognized output is an error with the offending line
+	if _, err = asa.ParseDisk("asa# dir /recursive all-filesystems\n%Error opening disk0:/ (No such device)\nasa# ", `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "Filesystem total not found in dir /recursive all-filesystems => %Error opening disk0:/ (No such device)") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
+
+func TestCheck_Disk(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckDisk(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+	if err != nil {
+		t.Errorf("Error CheckDisk: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,82 @@
+// This file content the typed errors returned by checks and the policy mapping them to an Icinga state,
+// so failures of the monitoring side are not reported as device outages
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+var (
+	// errConnection is returned when the SSH connection can't be established or is closed by the ASA
+	errConnection = errors.New("connection failed")
+	// errAuthFailed is returned when the ASA reject the username, password or keys
+	errAuthFailed = errors.New("authentication failed")
+	// errUnsupported is returned when the ASA reject a command (invalid input, command authorization)
+	errUnsupported = errors.New("command rejected")
+	// errParse is returned when the output of a command can't be parsed
+	errParse = errors.New("unparseable output")
+	// errConfig is returned when a local setting or file (option, state, known hosts, advisory) is invalid or unusable
+	errConfig = errors.New("configuration error")
+)
+
+// errorKinds associate the name of each kind of error used in policy with its typed error
+var errorKinds = []struct {
+	name string
+	err  error
+}{
+	{"hostkey", errHostKeyMismatch},
+	{"knownhost", errHostKeyUnknown},
+	{"auth", errAuthFailed},
+	{"enable", errEnableFailed},
+	{"connection", errConnection},
+	{"timeout", errTimeout},
+	{"unsupported", errUnsupported},
+	{"parse", errParse},
+	{"config", errConfig},
+}
+
+// defaultErrorPolicy is the state of each kind of error, other errors are critical
+const defaultErrorPolicy = "hostkey=critical,knownhost=unknown,auth=unknown,enable=unknown,connection=critical,timeout=unknown,unsupported=unknown,parse=unknown,config=unknown,other=critical"
+
+// parseErrorPolicy return the exit code of each kind of error from a comma separated list of kind=state,
+// kinds not in policy keep their default state
+func parseErrorPolicy(policy string) (map[string]int, error) {
+	var states = map[string]int{"ok": ict.OkExit, "warning": ict.WarExit, "critical": ict.CriExit, "unknown": ict.UnkExit}
+	exits := make(map[string]int)
+
+	for _, p := range []string{defaultErrorPolicy, policy} {
+		for _, item := range strings.Split(p, ",") {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			fields := strings.SplitN(item, "=", 2)
+			kind := strings.ToLower(strings.TrimSpace(fields[0]))
+			if _, found := exits[kind]; !found && p != defaultErrorPolicy {
+				return nil, fmt.Errorf("parseErrorPolicy, unknown error kind %s", kind)
+			}
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("parseErrorPolicy, missing state of error kind %s", kind)
+			}
+			exit, found := states[strings.ToLower(strings.TrimSpace(fields[1]))]
+			if !found {
+				return nil, fmt.Errorf("parseErrorPolicy, invalid state %s for error kind %s", fields[1], kind)
+			}
+			exits[kind] = exit
+		}
+	}
+	return exits, nil
+}
+
+// errorKind return the kind of err, other if err is not a typed error
+func errorKind(err error) string {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			return kind.name
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+func TestParseErrorPolicy(t *testing.T) {
+	policy, err := parseErrorPolicy("")
+	if err != nil {
+		t.Fatalf("Error parseErrorPolicy: %s", err)
+	}
+	if policy["auth"] != ict.UnkExit || policy["connection"] != ict.CriExit || policy["config"] != ict.UnkExit || policy["other"] != ict.CriExit {
+		t.Errorf("Error unexpected default policy %v", policy)
+	}
+
+	if policy, err = parseErrorPolicy("connection=unknown, Timeout=Critical"); err != nil {
+		t.Fatalf("Error parseErrorPolicy: %s", err)
+	}
+	if policy["connection"] != ict.UnkExit || policy["timeout"] != ict.CriExit || policy["auth"] != ict.UnkExit {
+		t.Errorf("Error unexpected policy %v", policy)
+	}
+
+	for _, invalid := range []string{"bogus=unknown", "auth", "auth=down"} {
+		if _, err = parseErrorPolicy(invalid); err == nil {
+			t.Errorf("Error invalid policy %s accepted", invalid)
+		}
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := map[error]string{
+		fmt.Errorf("openSession, %w for icinga", errAuthFailed):          "auth",
+		fmt.Errorf("openSession, %w: connection refused", errConnection): "connection",
+		fmt.Errorf("%w waiting output of show cpu (30s)", errTimeout):    "timeout",
+		rejectedError("show cpu", "Command authorization failed"):        "unsupported",
+		errEnableFailed: "enable",
+		fmt.Errorf("loadState, %w, invalid state file", errConfig): "config",
+		fmt.Errorf("ParseVersion, no version found"):               "other",
+	}
+	for err, want := range tests {
+		if kind := errorKind(err); kind != want {
+			t.Errorf("Error %s want kind %s got %s", err, want, kind)
+		}
+	}
+}
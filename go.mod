@@ -4,7 +4,10 @@ go 1.14
 
 require (
 	github.com/docopt/docopt-go v0.0.0-20180111231733-ee0de3bc6815
+	github.com/gosnmp/gosnmp v1.32.0
 	github.com/tdh-foundation/icinga2-go-checktools v1.0.1
+	golang.org/x/crypto v0.0.0-20191206172530-e9b2fee46413
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 // during development step
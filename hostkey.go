@@ -0,0 +1,117 @@
+// This file content the verification of the ASA and jump hosts keys against an OpenSSH known_hosts file or a pinned
+// fingerprint, unknown keys can be recorded on first use
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var (
+	// errHostKeyMismatch is returned when the ASA or a jump host present a key different from the known or pinned key
+	errHostKeyMismatch = errors.New("host key mismatch")
+	// errHostKeyUnknown is returned when the ASA or a jump host is not in known_hosts file and trust on first use is disabled
+	errHostKeyUnknown = errors.New("host key unknown")
+)
+
+// hostKeyCallback return the callback verifying a host key against fingerprint and known hosts file, keys
+// are not verified if neither known hosts file nor fingerprint is set. As ssh.Dial doesn't wrap the callback
+// error, the verification error is also stored in failure
+func (asa *CiscoASA) hostKeyCallback(fingerprint string, failure *error) (ssh.HostKeyCallback, error) {
+	file := asa.KnownHosts
+	if file == "" && asa.TOFU {
+		file = "~/.ssh/known_hosts"
+	}
+	if file == "" && fingerprint == "" {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	var known ssh.HostKeyCallback
+	if file != "" {
+		// Replacing tilde char by real home directory
+		if home, err := user.Current(); err == nil {
+			file = regexp.MustCompile(`^~(.*)$`).ReplaceAllString(file, home.HomeDir+"${1}")
+		}
+		// Known hosts file is created empty on first use
+		if _, err := os.Stat(file); os.IsNotExist(err) && asa.TOFU {
+			if err = os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+				return nil, fmt.Errorf("hostKeyCallback, %w, unable to create known hosts directory: %s", errConfig, err)
+			}
+			if err = ioutil.WriteFile(file, nil, 0600); err != nil {
+				return nil, fmt.Errorf("hostKeyCallback, %w, unable to create known hosts file: %s", errConfig, err)
+			}
+		}
+		var err error
+		if known, err = knownhosts.New(file); err != nil {
+			return nil, fmt.Errorf("hostKeyCallback, %w, unable to read known hosts file: %s", errConfig, err)
+		}
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		presented := ssh.FingerprintSHA256(key)
+
+		if fingerprint != "" && !matchFingerprint(fingerprint, key) {
+			*failure = fmt.Errorf("%w, %s presented %s key %s instead of %s", errHostKeyMismatch, hostname, key.Type(), presented, fingerprint)
+			return *failure
+		}
+		if known == nil {
+			return nil
+		}
+
+		var keyErr *knownhosts.KeyError
+		var revokedErr *knownhosts.RevokedError
+		err := known(hostname, remote, key)
+		switch {
+		case err == nil:
+			return nil
+		case errors.As(err, &keyErr) && len(keyErr.Want) > 0:
+			*failure = fmt.Errorf("%w, %s presented %s key %s not matching %s:%d", errHostKeyMismatch, hostname, key.Type(), presented, keyErr.Want[0].Filename, keyErr.Want[0].Line)
+		case errors.As(err, &keyErr) && asa.TOFU:
+			if err = appendKnownHost(file, hostname, key); err != nil {
+				*failure = err
+				return *failure
+			}
+			return nil
+		case errors.As(err, &keyErr):
+			*failure = fmt.Errorf("%w, %s %s key %s not found in %s", errHostKeyUnknown, hostname, key.Type(), presented, file)
+		case errors.As(err, &revokedErr):
+			*failure = fmt.Errorf("%w, %s presented revoked %s key %s", errHostKeyMismatch, hostname, key.Type(), presented)
+		default:
+			*failure = fmt.Errorf("hostKeyCallback, error verifying host key: %s", err)
+		}
+		return *failure
+	}, nil
+}
+
+// matchFingerprint return true if key match the fingerprint, SHA256 (SHA256:base64) and legacy
+// MD5 (MD5:hex pairs separated by colons) formats are accepted
+func matchFingerprint(fingerprint string, key ssh.PublicKey) bool {
+	if strings.HasPrefix(fingerprint, "SHA256:") {
+		return fingerprint == ssh.FingerprintSHA256(key)
+	}
+	return strings.EqualFold(strings.TrimPrefix(fingerprint, "MD5:"), ssh.FingerprintLegacyMD5(key))
+}
+
+// appendKnownHost record the key of hostname at the end of known hosts file
+func appendKnownHost(file string, hostname string, key ssh.PublicKey) error {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("appendKnownHost, %w, unable to open known hosts file: %s", errConfig, err)
+	}
+	defer f.Close()
+
+	if _, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+		return fmt.Errorf("appendKnownHost, %w, unable to write known hosts file: %s", errConfig, err)
+	}
+	return nil
+}
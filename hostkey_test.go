@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestCiscoASA_hostKey(t *testing.T) {
+	fake := &fakeASA{password: "secret", privileged: true}
+	port := fake.start(t)
+	address := knownhosts.Normalize(fmt.Sprintf("127.0.0.1:%d", port))
+
+	dir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "known_hosts")
+
+	// Pinned fingerprint
+	device := NewCiscoASA("asa")
+	for _, fingerprint := range []string{ssh.FingerprintSHA256(fake.hostKey), "MD5:" + ssh.FingerprintLegacyMD5(fake.hostKey)} {
+		device.HostKey = fingerprint
+		if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); err != nil {
+			t.Errorf("Error sendCommands with fingerprint %s: %s", fingerprint, err)
+		}
+	}
+	device.HostKey = "SHA256:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); !errors.Is(err, errHostKeyMismatch) {
+		t.Errorf("Error want %s got %v", errHostKeyMismatch, err)
+	}
+	device.HostKey = ""
+
+	// Unknown host without trust on first use
+	if err = ioutil.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	device.KnownHosts = file
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); !errors.Is(err, errHostKeyUnknown) {
+		t.Errorf("Error want %s got %v", errHostKeyUnknown, err)
+	}
+
+	// Trust on first use record the key, then the key is verified
+	device.TOFU = true
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); err != nil {
+		t.Errorf("Error sendCommands with trust on first use: %s", err)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := knownhosts.Line([]string{address}, fake.hostKey) + "\n"; string(data) != want {
+		t.Errorf("Error want known hosts %q got %q", want, data)
+	}
+	device.TOFU = false
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); err != nil {
+		t.Errorf("Error sendCommands with known host: %s", err)
+	}
+
+	// Another key recorded for the host is a mismatch, even with trust on first use
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(file, []byte(knownhosts.Line([]string{address}, other)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	device.TOFU = true
+	_, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil)
+	if !errors.Is(err, errHostKeyMismatch) || !strings.Contains(err.Error(), ssh.FingerprintSHA256(fake.hostKey)) {
+		t.Errorf("Error want %s got %v", errHostKeyMismatch, err)
+	}
+}
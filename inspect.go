@@ -0,0 +1,122 @@
+// This file content implementation of methods to check CISCO ASA service policies
+// inspection engines statistics
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckInspect check Cisco ASA inspection engines drop rates
+func (asa *CiscoASA) CheckInspect(host string, username string, password string, identity string, port int, critical string, warning string, stateDir string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show service-policy\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseInspect(stdout, stateFile(stateDir, host, "inspect.json"), critical, warning)
+}
+
+// ParseInspect parse the output of show service-policy and return Icinga result depending critical and warning
+// thresholds, drop rates are computed with the counters of the previous run
+func (asa *CiscoASA) ParseInspect(response string, file string, critical string, warning string) (ict.Icinga, error) {
+
+	var rePolicy = regexp.MustCompile(`(?i)^\s*Service-policy:\s*(?P<policy>\S+)\s*$`)
+	var reClass = regexp.MustCompile(`(?i)^\s*Class-map:\s*(?P<class>\S+)\s*$`)
+	var reInspect = regexp.MustCompile(`(?i)^\s*Inspect:\s*(?P<inspect>[^\s,]+)(?:\s+[^\s,]+)?\s*,\s*packet (?P<packets>\d+),.*?\bdrop (?P<drops>\d+), reset-drop (?P<resets>\d+)`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	commands := []string{"show service-policy"}
+	if err := checkOutput(response, commands); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for each inspection engine, it belongs to the last policy and class found
+	var inspects []map[string]string
+	policy, class := "", ""
+	for _, line := range strings.Split(strings.Replace(response, "\r", "", -1), "\n") {
+		if m := rePolicy.FindStringSubmatch(line); m != nil {
+			policy, class = m[1], ""
+		} else if m := reClass.FindStringSubmatch(line); m != nil {
+			class = m[1]
+		} else if m := reInspect.FindStringSubmatch(line); m != nil {
+			inspects = append(inspects, map[string]string{
+				"name": fmt.Sprintf("%s %s %s", policy, class, m[1]), "inspect": m[1],
+				"packets": m[2], "drops": m[3], "resets": m[4],
+			})
+		}
+	}
+	if len(inspects) == 0 {
+		return ict.Icinga{}, missingError(response, commands[0], "Inspection engine")
+	}
+
+	// Counters are reset on reload or clear service-policy
+	counters := make(map[string]int)
+	for _, inspect := range inspects {
+		counters[inspect["name"]+" packets"], _ = strconv.Atoi(inspect["packets"])
+		counters[inspect["name"]+" drops"], _ = strconv.Atoi(inspect["drops"])
+		counters[inspect["name"]+" reset-drops"], _ = strconv.Atoi(inspect["resets"])
+	}
+	deltas, err := counterDeltas(file, counters)
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	for _, inspect := range inspects {
+		name := inspect["name"]
+		packets, known := deltas[name+" packets"]
+		drops := deltas[name+" drops"] + deltas[name+" reset-drops"]
+
+		// Drop rate in % of packets inspected since previous run
+		if known && packets > 0 {
+			rate := float64(drops) * 100 / float64(packets)
+			if errCritical == nil && criticalTH.InspectDrop > 0 && rate > criticalTH.InspectDrop {
+				condition = ict.CriExit
+				message = appendMessage(message, fmt.Sprintf("%s inspection dropped %.1f%% of packets", inspect["inspect"], rate))
+			} else if errWarning == nil && warningTH.InspectDrop > 0 && rate > warningTH.InspectDrop {
+				if condition < ict.WarExit {
+					condition = ict.WarExit
+				}
+				message = appendMessage(message, fmt.Sprintf("%s inspection dropped %.1f%% of packets", inspect["inspect"], rate))
+			}
+			metrics += fmt.Sprintf("'%s drop rate'=%.2f%% ", inspect["name"], rate)
+		}
+		metrics += fmt.Sprintf("'%s packets'=%dc '%s drops'=%dc '%s reset-drops'=%dc ", name, counters[name+" packets"], name, counters[name+" drops"], name, counters[name+" reset-drops"])
+		details += fmt.Sprintf("\n%s: %d packets, %d drops, %d reset-drops", name, counters[name+" packets"], counters[name+" drops"], counters[name+" reset-drops"])
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, inspect := range inspects {
+			log.Printf("%s - %v\n", inspect["name"], inspect)
+		}
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("%d inspection engines Ok", len(inspects))
+	}
+	return ict.Icinga{Message: message + details, Exit: condition, Metric: metrics}, nil
+}
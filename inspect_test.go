@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	InspectResponse = `asa# show service-policy
+
+Global policy:
+  Service-policy: global_policy
+    Class-map: inspection_default
+      Inspect: dns preset_dns_map, packet 10000, lock fail 0, drop 10, reset-drop 0, 5-min-pkt-rate 2 pkts/sec, v6-fail-close 0 sctp-drop-override 0
+      Inspect: ftp, packet 150, lock fail 0, drop 0, reset-drop 0, 5-min-pkt-rate 0 pkts/sec, v6-fail-close 0 sctp-drop-override 0
+      Inspect: sip , packet 2000, lock fail 0, drop 0, reset-drop 0, 5-min-pkt-rate 0 pkts/sec, v6-fail-close 0 sctp-drop-override 0
+               tcp-proxy: bytes in buffer 0, bytes dropped 0
+Interface outside:
+  Service-policy: outside_policy
+    Class-map: icmp_class
+      Inspect: icmp, packet 3000, drop 0, reset-drop 0
+asa# `
+)
+
+func TestCiscoASA_ParseInspect(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	file := filepath.Join(stateDir, "asa_inspect.json")
+
+	icinga, err := asa.ParseInspect(InspectResponse, file, `{"inspect_drop":20}`, `{"inspect_drop":5}`)
+	if err != nil || icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.OkExit, icinga.Exit, icinga, err)
+	}
+	want := "4 inspection engines Ok\nglobal_policy inspection_default dns: 10000 packets, 10 drops, 0 reset-drops\nglobal_policy inspection_default ftp: 150 packets, 0 drops, 0 reset-drops\nglobal_policy inspection_default sip: 2000 packets, 0 drops, 0 reset-drops\noutside_policy icmp_class icmp: 3000 packets, 0 drops, 0 reset-drops"
+	if icinga.Message != want {
+		t.Errorf("Error want message\n%s\ngot\n%s", want, icinga.Message)
+	}
+	if !strings.HasPrefix(icinga.Metric, "'global_policy inspection_default dns packets'=10000c 'global_policy inspection_default dns drops'=10c ") {
+		t.Errorf("Error unexpected metrics %s", icinga.Metric)
+	}
+
+	// SIP inspection breaking calls after an upgrade
+	broken := strings.Replace(InspectResponse, "sip , packet 2000, lock fail 0, drop 0, reset-drop 0", "sip , packet 2100, lock fail 0, drop 20, reset-drop 10", 1)
+	broken = strings.Replace(broken, "dns preset_dns_map, packet 10000, lock fail 0, drop 10", "dns preset_dns_map, packet 11000, lock fail 0, drop 20", 1)
+	icinga, _ = asa.ParseInspect(broken, file, `{"inspect_drop":20}`, `{"inspect_drop":5}`)
+	if icinga.Exit != ict.CriExit || !strings.HasPrefix(icinga.Message, "sip inspection dropped 30.0% of packets\n") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+	if !strings.Contains(icinga.Metric, "'global_policy inspection_default dns drop rate'=1.00% ") {
+		t.Errorf("Error unexpected metrics %s", icinga.Metric)
+	}
+
+	// Unrecognized output is an error with the offending line
+	if _, err = asa.ParseInspect("asa# show service-policy\nasa# ", file, `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "Inspection engine not found in show service-policy => empty output") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
+
+func TestCheck_Inspect(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckInspect(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+	if err != nil {
+		t.Errorf("Error CheckInspect: %s", err)
+	}
+	t.Log(icinga)
+}
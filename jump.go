@@ -0,0 +1,70 @@
+// This file content the jump hosts (ProxyJump) the SSH connection to the CISCO ASA is tunneled through
+// when the management network is only reachable from a bastion
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// JumpHost is a SSH server forwarding the connection to the next hop
+type JumpHost struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Identity string // Private key file
+	HostKey  string // Pinned fingerprint of the jump host key
+}
+
+// address return host:port of the jump host
+func (j JumpHost) address() string {
+	return net.JoinHostPort(j.Host, strconv.Itoa(j.Port))
+}
+
+// parseJumps parse a comma separated list of jump hosts in OpenSSH ProxyJump format [user@]host[:port],
+// username and identity are used when the jump host doesn't define them
+func parseJumps(spec string, username string, identity string) ([]JumpHost, error) {
+	var jumps []JumpHost
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		jump := JumpHost{Host: item, Port: 22, Username: username, Identity: identity}
+		if i := strings.LastIndex(item, "@"); i >= 0 {
+			jump.Username, jump.Host = item[:i], item[i+1:]
+		}
+		if host, port, err := net.SplitHostPort(jump.Host); err == nil {
+			if jump.Port, err = strconv.Atoi(port); err != nil || jump.Port <= 0 || jump.Port > 65535 {
+				return nil, fmt.Errorf("parseJumps, invalid port in jump host %s", item)
+			}
+			jump.Host = host
+		}
+		jump.Host = strings.Trim(jump.Host, "[]")
+		if jump.Host == "" || jump.Username == "" {
+			return nil, fmt.Errorf("parseJumps, invalid jump host %s", item)
+		}
+		jumps = append(jumps, jump)
+	}
+	return jumps, nil
+}
+
+// pinJumpHostKeys set the fingerprints of a comma separated list in jump hosts order, an empty fingerprint
+// leave the jump host key to the known hosts file
+func pinJumpHostKeys(jumps []JumpHost, spec string) error {
+	if strings.TrimSpace(spec) == "" {
+		return nil
+	}
+	fingerprints := strings.Split(spec, ",")
+	if len(fingerprints) != len(jumps) {
+		return fmt.Errorf("pinJumpHostKeys, %d fingerprints given for %d jump hosts", len(fingerprints), len(jumps))
+	}
+	for i, fingerprint := range fingerprints {
+		jumps[i].HostKey = strings.TrimSpace(fingerprint)
+	}
+	return nil
+}
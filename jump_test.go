@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseJumps(t *testing.T) {
+	jumps, err := parseJumps("bastion.example.com, admin@10.0.0.1:2222,[2001:db8::1]:22,jump@[2001:db8::2]", "icinga", "~/.ssh/id_rsa")
+	if err != nil {
+		t.Fatalf("Error parseJumps: %s", err)
+	}
+	want := []JumpHost{
+		{Host: "bastion.example.com", Port: 22, Username: "icinga", Identity: "~/.ssh/id_rsa"},
+		{Host: "10.0.0.1", Port: 2222, Username: "admin", Identity: "~/.ssh/id_rsa"},
+		{Host: "2001:db8::1", Port: 22, Username: "icinga", Identity: "~/.ssh/id_rsa"},
+		{Host: "2001:db8::2", Port: 22, Username: "jump", Identity: "~/.ssh/id_rsa"},
+	}
+	if !reflect.DeepEqual(jumps, want) {
+		t.Errorf("Error want %v got %v", want, jumps)
+	}
+	if jumps[2].address() != "[2001:db8::1]:22" {
+		t.Errorf("Error unexpected address %s", jumps[2].address())
+	}
+
+	if jumps, err = parseJumps("", "icinga", ""); err != nil || jumps != nil {
+		t.Errorf("Error want no jump host got %v (%v)", jumps, err)
+	}
+	for _, invalid := range []string{"bastion:ssh", "bastion:0", "@bastion"} {
+		if _, err = parseJumps(invalid, "icinga", ""); err == nil {
+			t.Errorf("Error invalid jump host %s accepted", invalid)
+		}
+	}
+}
+
+func TestCiscoASA_sendCommandsJump(t *testing.T) {
+	fake := &fakeASA{password: "secret", privileged: true, responses: map[string]string{"show clock": "12:00:00.000 CEST Mon Jun 1 2020\n"}}
+	port := fake.start(t)
+	bastion1 := &fakeASA{password: "jump1-secret", forward: true}
+	port1 := bastion1.start(t)
+	bastion2 := &fakeASA{password: "jump2-secret", forward: true}
+	port2 := bastion2.start(t)
+
+	// Connection tunneled through two jump hosts with their own passwords
+	device := NewCiscoASA("asa")
+	device.Jumps = []JumpHost{
+		{Host: "127.0.0.1", Port: port1, Username: "jump", Password: "jump1-secret"},
+		{Host: "127.0.0.1", Port: port2, Username: "jump", Password: "jump2-secret"},
+	}
+	stdout, err := device.sendCommands("127.0.0.1", "icinga", "secret", "", port, []string{"show clock\n"})
+	if err != nil {
+		t.Fatalf("Error sendCommands through jump hosts: %s", err)
+	}
+	if output := strings.Join(commandOutput(stdout, "show clock"), "\n"); output != "12:00:00.000 CEST Mon Jun 1 2020" {
+		t.Errorf("Error unexpected output %q", output)
+	}
+	for bastion, want := range map[*fakeASA]string{bastion1: fmt.Sprintf("forward 127.0.0.1:%d", port2), bastion2: fmt.Sprintf("forward 127.0.0.1:%d", port)} {
+		bastion.mu.Lock()
+		if strings.Join(bastion.received, ",") != want {
+			t.Errorf("Error want %s got %v", want, bastion.received)
+		}
+		bastion.mu.Unlock()
+	}
+
+	// Keys of ASA and jump hosts are recorded on first use
+	dir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	device.KnownHosts = filepath.Join(dir, "known_hosts")
+	device.TOFU = true
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); err != nil {
+		t.Errorf("Error sendCommands with trust on first use: %s", err)
+	}
+	if data, err := ioutil.ReadFile(device.KnownHosts); err != nil || strings.Count(string(data), "\n") != 3 {
+		t.Errorf("Error want 3 known hosts got %q (%v)", data, err)
+	}
+
+	// Jump host password rejected
+	device.Jumps[1].Password = "wrong"
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); !errors.Is(err, errAuthFailed) || !strings.Contains(err.Error(), fmt.Sprintf("jump@127.0.0.1:%d", port2)) {
+		t.Errorf("Error want %s of jump host got %v", errAuthFailed, err)
+	}
+
+	// ASA unreachable from the jump host
+	device.Jumps = device.Jumps[:1]
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", 1, nil); !errors.Is(err, errConnection) || !strings.Contains(err.Error(), "unable to reach 127.0.0.1:1") {
+		t.Errorf("Error want %s got %v", errConnection, err)
+	}
+}
+
+func TestCiscoASA_sendCommandsJumpHostKey(t *testing.T) {
+	fake := &fakeASA{password: "secret", privileged: true}
+	port := fake.start(t)
+	bastion := &fakeASA{password: "jump-secret", forward: true}
+	bastionPort := bastion.start(t)
+
+	// ASA and jump host keys pinned
+	device := NewCiscoASA("asa")
+	device.HostKey = ssh.FingerprintSHA256(fake.hostKey)
+	device.Jumps = []JumpHost{{Host: "127.0.0.1", Port: bastionPort, Username: "jump", Password: "jump-secret"}}
+	if err := pinJumpHostKeys(device.Jumps, ssh.FingerprintSHA256(bastion.hostKey)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); err != nil {
+		t.Errorf("Error sendCommands with pinned jump host key: %s", err)
+	}
+
+	// Jump host key mismatch
+	device.Jumps[0].HostKey = ssh.FingerprintSHA256(fake.hostKey)
+	if _, err := device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); !errors.Is(err, errHostKeyMismatch) {
+		t.Errorf("Error want %s got %v", errHostKeyMismatch, err)
+	}
+
+	// Jump host key neither pinned nor in known hosts file while ASA key is verified
+	device.Jumps[0].HostKey = ""
+	bastion.mu.Lock()
+	bastion.received = nil
+	bastion.mu.Unlock()
+	if _, err := device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); !errors.Is(err, errHostKeyUnknown) {
+		t.Errorf("Error want %s got %v", errHostKeyUnknown, err)
+	}
+	bastion.mu.Lock()
+	if len(bastion.received) != 0 {
+		t.Errorf("Error jump host reached %v", bastion.received)
+	}
+	bastion.mu.Unlock()
+
+	if err := pinJumpHostKeys(device.Jumps, "SHA256:a,SHA256:b"); err == nil {
+		t.Errorf("Error fingerprints count not checked")
+	}
+}
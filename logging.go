@@ -0,0 +1,147 @@
+// This file content implementation of methods to check CISCO ASA logging subsystem and
+// syslog servers state
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckLogging check Cisco ASA logging settings, syslog servers and dropped messages
+func (asa *CiscoASA) CheckLogging(host string, username string, password string, identity string, port int, critical string, warning string, stateDir string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show logging setting\n", "show logging queue\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseLogging(stdout, stateFile(stateDir, host, "logging.json"), critical, warning)
+}
+
+// ParseLogging parse logging settings and queue and return Icinga result depending critical and warning
+// thresholds, dropped messages are compared with the previous run
+func (asa *CiscoASA) ParseLogging(response string, file string, critical string, warning string) (ict.Icinga, error) {
+
+	var reEnabled = regexp.MustCompile(`(?mi)^\s*Syslog logging:\s*(?P<state>\w+).*$`)
+	var reBuffer = regexp.MustCompile(`(?mi)^\s*Buffer logging:\s*(?P<setting>.+?)\s*$`)
+	var reTrap = regexp.MustCompile(`(?mi)^\s*Trap logging:\s*(?P<setting>.+?)\s*$`)
+	var reServer = regexp.MustCompile(`(?mi)^\s*Logging to (?P<interface>\S+) (?P<host>[^\s,]+)(?:\s+(?P<protocol>(?:tcp|udp)/\d+))?(?P<state>.*?)\s*$`)
+	var reServerCounter = regexp.MustCompile(`(?i)(errors|dropped):\s*(\d+)`)
+	var reServerDown = regexp.MustCompile(`(?i)(not connected|disconnected|unreachable|unavailable|error|blocked)`)
+	var reDropped = regexp.MustCompile(`(?mi)^\s*(?P<count>\d+) (?:msg\(s\)|messages) (?:discarded|dropped|rate-limited)(?P<reason>.*?)\s*$`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+
+	commands := []string{"show logging setting", "show logging queue"}
+	if err := checkOutput(response, commands); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	//
+	// Parsing returned data
+	//
+	enabled := reEnabled.FindStringSubmatch(response)
+	if enabled == nil {
+		return ict.Icinga{}, missingError(response, commands[0], "Syslog logging")
+	}
+	if strings.ToLower(enabled[1]) != "enabled" {
+		return ict.Icinga{Message: fmt.Sprintf("Syslog logging %s", enabled[1]), Exit: ict.CriExit}, nil
+	}
+
+	// Creating map for syslog servers
+	keys := reServer.SubexpNames()[1:]
+	var servers []map[string]string
+	for _, s := range reServer.FindAllStringSubmatch(response, -1) {
+		server := make(map[string]string)
+		for i, v := range s[1:] {
+			server[keys[i]] = v
+		}
+		// State without errors and dropped counters
+		server["state"] = strings.Trim(reServerCounter.ReplaceAllString(server["state"], ""), " ,")
+		server["errors"], server["dropped"] = "0", "0"
+		for _, counter := range reServerCounter.FindAllStringSubmatch(s[0], -1) {
+			server[strings.ToLower(counter[1])] = counter[2]
+		}
+		servers = append(servers, server)
+	}
+
+	// Dropped messages of logging queue and servers
+	dropped := 0
+	for _, s := range reDropped.FindAllStringSubmatch(response, -1) {
+		count, _ := strconv.Atoi(s[1])
+		dropped += count
+	}
+	for _, server := range servers {
+		count, _ := strconv.Atoi(server["dropped"])
+		dropped += count
+	}
+
+	for _, server := range servers {
+		name := fmt.Sprintf("%s (%s)", server["host"], server["interface"])
+		if reServerDown.MatchString(server["state"]) {
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("Syslog server %s %s", name, server["state"]))
+		}
+		metrics += fmt.Sprintf("'%s errors'=%sc ", server["host"], server["errors"])
+		metrics += fmt.Sprintf("'%s dropped'=%sc ", server["host"], server["dropped"])
+	}
+
+	// Comparing dropped messages with the previous run, counters are reset on reload
+	deltas, err := counterDeltas(file, map[string]int{"dropped": dropped})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+	newDropped, known := deltas["dropped"]
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	// Without warning threshold any new dropped message raise a warning
+	if known && newDropped > 0 {
+		if errCritical == nil && criticalTH.LogDropped > 0 && newDropped > criticalTH.LogDropped {
+			condition = ict.CriExit
+		} else if (errWarning != nil || newDropped > warningTH.LogDropped) && condition < ict.WarExit {
+			condition = ict.WarExit
+		}
+		message = appendMessage(message, fmt.Sprintf("%d log messages dropped since last check", newDropped))
+	}
+	metrics += fmt.Sprintf("'Dropped messages'=%dc ", dropped)
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, server := range servers {
+			log.Printf("%s %s %s - %s - errors %s - dropped %s\n", server["interface"], server["host"], server["protocol"], server["state"], server["errors"], server["dropped"])
+		}
+		log.Printf("Dropped messages %d, new %d\n", dropped, newDropped)
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Logging enabled, %d syslog servers Ok", len(servers))
+	}
+
+	// Logging settings in long output
+	if buffer := reBuffer.FindStringSubmatch(response); buffer != nil {
+		message += fmt.Sprintf("\nBuffer logging: %s", buffer[1])
+	}
+	if trap := reTrap.FindStringSubmatch(response); trap != nil {
+		message += fmt.Sprintf("\nTrap logging: %s", trap[1])
+	}
+	for _, server := range servers {
+		message += "\n" + strings.Join(strings.Fields(fmt.Sprintf("%s %s %s %s", server["interface"], server["host"], server["protocol"], server["state"])), " ")
+	}
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	LoggingResponse = `asa# show logging setting
+Syslog logging: enabled
+    Facility: 20
+    Timestamp logging: enabled
+    Hide Username logging: enabled
+    Standby logging: disabled
+    Debug-trace logging: disabled
+    Console logging: disabled
+    Monitor logging: disabled
+    Buffer logging: level informational, 2457 messages logged
+    Trap logging: level informational, facility 20, 123456 messages logged
+        Logging to inside 10.1.1.50 errors: 0 dropped: 0
+        Logging to inside 10.1.1.51 tcp/1470 Connected
+    Permit-hostdown logging: disabled
+    History logging: disabled
+    Device ID: disabled
+    Mail logging: disabled
+    ASDM logging: level informational, 12345 messages logged
+asa# show logging queue
+
+        Logging Queue length limit : 512 msg(s)
+        0 msg(s) in queue, 10 msg(s) most on queue
+        0 msg(s) discarded due to queue overflow
+        0 msg(s) discarded due to memory allocation failure
+asa# `
+)
+
+func TestCiscoASA_ParseLogging(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	file := filepath.Join(stateDir, "asa_logging.json")
+
+	icinga, err := asa.ParseLogging(LoggingResponse, file, `{}`, `{}`)
+	if err != nil || icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.OkExit, icinga.Exit, icinga, err)
+	}
+	want := "Logging enabled, 2 syslog servers Ok\nBuffer logging: level informational, 2457 messages logged\nTrap logging: level informational, facility 20, 123456 messages logged\ninside 10.1.1.50\ninside 10.1.1.51 tcp/1470 Connected"
+	if icinga.Message != want {
+		t.Errorf("Error want message\n%s\ngot\n%s", want, icinga.Message)
+	}
+
+	// Dropped messages and TCP syslog server down
+	down := strings.Replace(LoggingResponse, "tcp/1470 Connected", "tcp/1470 Not connected, new connections blocked", 1)
+	down = strings.Replace(down, "errors: 0 dropped: 0", "errors: 12 dropped: 30", 1)
+	down = strings.Replace(down, "0 msg(s) discarded due to queue overflow", "20 msg(s) discarded due to queue overflow", 1)
+	icinga, _ = asa.ParseLogging(down, file, `{"log_dropped":100}`, `{}`)
+	if icinga.Exit != ict.CriExit || !strings.HasPrefix(icinga.Message, "Syslog server 10.1.1.51 (inside) Not connected, new connections blocked/50 log messages dropped since last check") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+	if !strings.Contains(icinga.Metric, "'10.1.1.50 errors'=12c '10.1.1.50 dropped'=30c") || !strings.Contains(icinga.Metric, "'Dropped messages'=50c") {
+		t.Errorf("Error unexpected metrics %s", icinga.Metric)
+	}
+
+	// Unrecognized output is an error with the offending line
+	if _, err = asa.ParseLogging("asa# show logging setting\nLogging disabled on this context\nasa# ", file, `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "Syslog logging not found in show logging setting => Logging disabled on this context") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+
+	disabled := strings.Replace(LoggingResponse, "Syslog logging: enabled", "Syslog logging: disabled", 1)
+	icinga, _ = asa.ParseLogging(disabled, file, `{}`, `{}`)
+	if icinga.Exit != ict.CriExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+}
+
+func TestCheck_Logging(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckLogging(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+	if err != nil {
+		t.Errorf("Error CheckLogging: %s", err)
+	}
+	t.Log(icinga)
+}
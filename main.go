@@ -8,6 +8,8 @@ import (
 	ict "github.com/tdh-foundation/icinga2-go-checktools"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // version of program
@@ -31,19 +33,72 @@ var (
 		switchType string
 		critical   string
 		warning    string
+		stateDir   string
+		ignore     []string
+		accept     bool
+		advisory   string
+		rules      []string
+		report     bool
+		enable     string
+		checks     []string
+		passive    bool
+		icingaHost string
+		services   map[string]string
+		agent      bool
+		knownHosts string
+		hostKey    string
+		tofu       bool
+
+		connectTimeout int
+		commandTimeout int
+		timeout        int
+		retries        int
+		retryDelay     int
+		errorPolicy    map[string]int
+		jumps          []JumpHost
+
+		transport string
+		snmp      SNMPConfig
 	}
 )
 
 // Init parsing program arguments
 func init() {
+	var policy string
+	var jump string
+	var jumpHostKey string
+	var snmpTimeout int
+	var servicePrefix string
+	var serviceMap string
+	var credentialsFile string
+	var credentialsIdentity string
 
 	usage = `check_ciscoasa
 Check CISCO ASA status
 Usage: 
 	check_ciscoasa (-h | --help | --version)
-	check_ciscoasa status (-H <host> | --host=<host>) (-u <username> | --username=<username>) (-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>) [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
-	check_ciscoasa vpnusers (-H <host> | --host=<host>) (-u <username> | --username=<username>) (-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>) [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
-	check_ciscoasa failover (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa status (-H <host> | --host=<host>) (-u <username> | --username=<username>) (-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>) [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa vpnusers (-H <host> | --host=<host>) (-u <username> | --username=<username>) (-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>) [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa failover (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa status --transport=snmp (-H <host> | --host=<host>) (-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>) [--snmp-version=<version>] [--community=<community>] [--snmp-port=<port>] [--snmp-user=<user>] [--auth-protocol=<protocol>] [--auth-password=<password>] [--priv-protocol=<protocol>] [--priv-password=<password>] [--snmp-timeout=<seconds>] [--snmp-retries=<count>] [--timeout=<seconds>] [--error-policy=<policy>] [--verbose] 
+	check_ciscoasa vpnusers --transport=snmp (-H <host> | --host=<host>) (-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>) [--snmp-version=<version>] [--community=<community>] [--snmp-port=<port>] [--snmp-user=<user>] [--auth-protocol=<protocol>] [--auth-password=<password>] [--priv-protocol=<protocol>] [--priv-password=<password>] [--snmp-timeout=<seconds>] [--snmp-retries=<count>] [--timeout=<seconds>] [--error-policy=<policy>] [--verbose] 
+	check_ciscoasa failover --transport=snmp (-H <host> | --host=<host>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--snmp-version=<version>] [--community=<community>] [--snmp-port=<port>] [--snmp-user=<user>] [--auth-protocol=<protocol>] [--auth-password=<password>] [--priv-protocol=<protocol>] [--priv-password=<password>] [--snmp-timeout=<seconds>] [--snmp-retries=<count>] [--timeout=<seconds>] [--error-policy=<policy>] [--verbose] 
+	check_ciscoasa threats (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa ntp (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa config (-H <host> | --host=<host>) (-u <username> | --username=<username>) [--state-dir=<dir>] [--ignore=<pattern>...] [--accept] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa unsaved (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--state-dir=<dir>] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa version (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--advisory=<file>] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa uptime (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--state-dir=<dir>] [--accept] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa disk (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa logging (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--state-dir=<dir>] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa aaa (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--state-dir=<dir>] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa sla (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--state-dir=<dir>] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa dhcp (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa acl (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--state-dir=<dir>] [--rule=<pattern>...] [--report] [--accept] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa modules (-H <host> | --host=<host>) (-u <username> | --username=<username>) [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa inspect (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--state-dir=<dir>] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa traffic (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
+	check_ciscoasa multi (-H <host> | --host=<host>) (-u <username> | --username=<username>) [(-c <critical> | --critical=<critical>) (-w <warning> | --warning=<warning>)] [--checks=<list>] [--passive] [--icinga-host=<name>] [--service-prefix=<prefix>] [--service-map=<mapping>] [--state-dir=<dir>] [--advisory=<file>] [--enable=<password> | --enable-file=<file>] [--password-file=<file>] [--credentials=<file>] [--ssh-agent] [--known-hosts=<file>] [--host-key=<fingerprint>] [--tofu] [--connect-timeout=<seconds>] [--command-timeout=<seconds>] [--timeout=<seconds>] [--retries=<count>] [--retry-delay=<seconds>] [--jump=<hosts>] [--jump-host-key=<fingerprints>] [--error-policy=<policy>] [-p <password> | --password=<password> | -i <pkey_file> | --identity=<pkey_file] [-P <port> | --port=<port>] [--verbose] 
 Options:
 	--version  				Show check_ciscoasa version.
 	-h --help  				Show this screen.
@@ -53,11 +108,50 @@ Options:
 	-p <password> --password=<password>  	Password
 	-i <pkey_file> --identity=<pkey_file>  	Private key file [default: ~/.ssh/id_rsa]
 	-P <port> --port=<port>  		Port number [default: 22]
-	-c <critical> --critical=<critical>		Critical threshold in JSON format example {"cpu":[90,70,50],"free_memory":50,"vpn_users":250,"failover_active":900} 
-	-w <warning> --warning=<warning>		Warning threshold in JSON format example {"cpu":[70,50,30],"free_memory":50,"vpn_users":200,"failover_active":1800}`
+	--enable=<password>  		Enable password, CHECK_CISCOASA_ENABLE environment variable is used if not set
+	--enable-file=<file>  		File containing the enable password
+	--password-file=<file>  		File containing the password, CHECK_CISCOASA_PASSWORD environment variable is used if no password is given
+	--credentials=<file>  		Netrc like file of passwords by host (machine <host> login <username> password <password> enable <password>)
+	--ssh-agent  				Use keys of SSH agent (SSH_AUTH_SOCK) for authentication
+	--known-hosts=<file>  		OpenSSH known_hosts file used to verify the ASA host key
+	--host-key=<fingerprint>  		Pinned fingerprint of the ASA host key (SHA256:... or MD5:...)
+	--tofu  				Trust on first use, record unknown host keys in known hosts file (~/.ssh/known_hosts by default)
+	--connect-timeout=<seconds>  		Maximum time to connect, authenticate and open the shell [default: 10]
+	--command-timeout=<seconds>  		Maximum wait for the output of each command [default: 30]
+	--timeout=<seconds>  		Maximum duration of the SSH session, keep it below Icinga check timeout [default: 50]
+	--retries=<count>  		Number of retries when connection is refused, reset or maximum SSH sessions is reached [default: 2]
+	--retry-delay=<seconds>  		Delay before first retry, doubled at each retry up to 60 seconds with random jitter, retries stop at session timeout [default: 1]
+	--jump=<hosts>  		Comma separated jump hosts [user@]host[:port] the connection is tunneled through (ProxyJump), their passwords and keys are taken from credentials file
+	--jump-host-key=<fingerprints>  		Comma separated pinned fingerprints of jump hosts keys in --jump order, empty to use known hosts file
+	--error-policy=<policy>  		Comma separated states of plugin errors overriding defaults (hostkey=critical,knownhost=unknown,auth=unknown,enable=unknown,connection=critical,timeout=unknown,unsupported=unknown,parse=unknown,config=unknown,other=critical)
+	-c <critical> --critical=<critical>		Critical threshold in JSON format example {"cpu":[90,70,50],"free_memory":50,"vpn_users":250,"failover_active":900,"threat_rate":[100,500],"shunned_hosts":5,"ntp_offset":500,"clock_drift":60,"unsaved_time":86400,"reload_uptime":3600,"uptime":600,"crash_hold":86400,"disk_free":10,"core_files":10,"log_dropped":1000,"aaa_timeouts":10,"track_changes":5,"primary_tracks":[1],"dhcp_pool":95,"acl_rate":1000,"inspect_drop":10,"traffic_usage":90} 
+	-w <warning> --warning=<warning>		Warning threshold in JSON format example {"cpu":[70,50,30],"free_memory":50,"vpn_users":200,"failover_active":1800,"threat_rate":[50,200],"ntp_offset":100,"clock_drift":10,"unsaved_time":3600,"reload_uptime":86400,"uptime":3600,"disk_free":20,"core_files":1,"dhcp_pool":85,"acl_rate":100,"inspect_drop":1,"traffic_usage":80}
+	--state-dir=<dir>  		Directory where baselines and previous values are stored [default: /var/tmp/check_ciscoasa]
+	--ignore=<pattern>  		Regular expression of configuration lines ignored by config command (can be repeated)
+	--accept  				Accept current state as new baseline (config, acl and uptime commands)
+	--advisory=<file>  		JSON or YAML file of advisories and end of support versions
+	--rule=<pattern>  		Regular expression of access list entries monitored by acl command, all deny entries by default (can be repeated)
+	--report  				Report access list entries without hits since baseline
+	--checks=<list>  		Comma separated checks evaluated by multi command [default: status,vpnusers,failover]
+	--passive  				Print each check result of multi command as Icinga external command
+	--icinga-host=<name>  		Icinga host of passive results, host address if not set
+	--service-prefix=<prefix>  		Prefix of check names in passive results service names
+	--service-map=<mapping>  		Comma separated service names of checks in passive results (check=service), overriding prefix
+	--transport=<transport>  		Transport of status, vpnusers and failover commands, ssh or snmp [default: ssh]
+	--snmp-version=<version>  		SNMP version, 2c or 3 [default: 2c]
+	--community=<community>  		SNMPv2c community, CHECK_CISCOASA_COMMUNITY environment variable is used if not set, public otherwise
+	--snmp-port=<port>  		SNMP agent port [default: 161]
+	--snmp-user=<user>  		SNMPv3 security name
+	--auth-protocol=<protocol>  		SNMPv3 authentication protocol (MD5, SHA, SHA224, SHA256, SHA384, SHA512)
+	--auth-password=<password>  		SNMPv3 authentication password
+	--priv-protocol=<protocol>  		SNMPv3 privacy protocol (DES, AES, AES192, AES256)
+	--priv-password=<password>  		SNMPv3 privacy password
+	--snmp-timeout=<seconds>  		Timeout of each SNMP request, doubled at each retransmission [default: 1]
+	--snmp-retries=<count>  		Number of retransmissions of a SNMP request [default: 2]`
 
 	// Don't parse command line argument for testing argument must be passed with OS environment variable
-	if os.Getenv("CHECK_MODE") == "TEST" {
+	// (go test binaries are always considered in test mode)
+	if os.Getenv("CHECK_MODE") == "TEST" || strings.HasSuffix(strings.TrimSuffix(os.Args[0], ".exe"), ".test") {
 		params.version, _ = strconv.ParseBool(os.Getenv("VERSION"))
 		params.port, _ = strconv.Atoi(os.Getenv("PORT"))
 		if params.port == 0 {
@@ -74,6 +168,81 @@ Options:
 		params.command = os.Getenv("COMMAND")
 		params.critical = os.Getenv("CRITICAL")
 		params.warning = os.Getenv("WARNING")
+		params.stateDir = os.Getenv("STATE_DIR")
+		if params.stateDir == "" {
+			params.stateDir = "/var/tmp/check_ciscoasa"
+		}
+		if os.Getenv("IGNORE") != "" {
+			params.ignore = strings.Split(os.Getenv("IGNORE"), "\n")
+		}
+		params.accept, _ = strconv.ParseBool(os.Getenv("ACCEPT"))
+		params.advisory = os.Getenv("ADVISORY")
+		if os.Getenv("RULES") != "" {
+			params.rules = strings.Split(os.Getenv("RULES"), "\n")
+		}
+		params.report, _ = strconv.ParseBool(os.Getenv("REPORT"))
+		params.enable = os.Getenv("ENABLE")
+		params.checks = strings.Split(os.Getenv("CHECKS"), ",")
+		if os.Getenv("CHECKS") == "" {
+			params.checks = []string{"status", "vpnusers", "failover"}
+		}
+		params.passive, _ = strconv.ParseBool(os.Getenv("PASSIVE"))
+		params.icingaHost = os.Getenv("ICINGA_HOST")
+		servicePrefix = os.Getenv("SERVICE_PREFIX")
+		serviceMap = os.Getenv("SERVICE_MAP")
+		params.agent, _ = strconv.ParseBool(os.Getenv("SSH_AGENT"))
+		params.knownHosts = os.Getenv("KNOWN_HOSTS")
+		params.hostKey = os.Getenv("HOST_KEY")
+		params.tofu, _ = strconv.ParseBool(os.Getenv("TOFU"))
+		params.connectTimeout, _ = strconv.Atoi(os.Getenv("CONNECT_TIMEOUT"))
+		if params.connectTimeout == 0 {
+			params.connectTimeout = 10
+		}
+		params.commandTimeout, _ = strconv.Atoi(os.Getenv("COMMAND_TIMEOUT"))
+		if params.commandTimeout == 0 {
+			params.commandTimeout = 30
+		}
+		params.timeout, _ = strconv.Atoi(os.Getenv("TIMEOUT"))
+		if params.timeout == 0 {
+			params.timeout = 50
+		}
+		params.retries, _ = strconv.Atoi(os.Getenv("RETRIES"))
+		if os.Getenv("RETRIES") == "" {
+			params.retries = 2
+		}
+		params.retryDelay, _ = strconv.Atoi(os.Getenv("RETRY_DELAY"))
+		if params.retryDelay == 0 {
+			params.retryDelay = 1
+		}
+		policy = os.Getenv("ERROR_POLICY")
+		jump = os.Getenv("JUMP")
+		jumpHostKey = os.Getenv("JUMP_HOST_KEY")
+		params.transport = os.Getenv("TRANSPORT")
+		if params.transport == "" {
+			params.transport = "ssh"
+		}
+		params.snmp.Version = os.Getenv("SNMP_VERSION")
+		if params.snmp.Version == "" {
+			params.snmp.Version = "2c"
+		}
+		params.snmp.Port, _ = strconv.Atoi(os.Getenv("SNMP_PORT"))
+		if params.snmp.Port == 0 {
+			params.snmp.Port = 161
+		}
+		params.snmp.Community = os.Getenv("COMMUNITY")
+		params.snmp.Username = os.Getenv("SNMP_USER")
+		params.snmp.AuthProtocol = os.Getenv("AUTH_PROTOCOL")
+		params.snmp.AuthPassword = os.Getenv("AUTH_PASSWORD")
+		params.snmp.PrivProtocol = os.Getenv("PRIV_PROTOCOL")
+		params.snmp.PrivPassword = os.Getenv("PRIV_PASSWORD")
+		snmpTimeout, _ = strconv.Atoi(os.Getenv("SNMP_TIMEOUT"))
+		if snmpTimeout == 0 {
+			snmpTimeout = 1
+		}
+		params.snmp.Retries, _ = strconv.Atoi(os.Getenv("SNMP_RETRIES"))
+		if os.Getenv("SNMP_RETRIES") == "" {
+			params.snmp.Retries = 2
+		}
 	} else {
 		arguments, err = docopt.ParseDoc(usage)
 		if err != nil {
@@ -90,6 +259,54 @@ Options:
 		if c, _ := arguments.Bool("failover"); c {
 			params.command = "failover"
 		}
+		if c, _ := arguments.Bool("threats"); c {
+			params.command = "threats"
+		}
+		if c, _ := arguments.Bool("ntp"); c {
+			params.command = "ntp"
+		}
+		if c, _ := arguments.Bool("config"); c {
+			params.command = "config"
+		}
+		if c, _ := arguments.Bool("unsaved"); c {
+			params.command = "unsaved"
+		}
+		if c, _ := arguments.Bool("version"); c {
+			params.command = "version"
+		}
+		if c, _ := arguments.Bool("uptime"); c {
+			params.command = "uptime"
+		}
+		if c, _ := arguments.Bool("disk"); c {
+			params.command = "disk"
+		}
+		if c, _ := arguments.Bool("logging"); c {
+			params.command = "logging"
+		}
+		if c, _ := arguments.Bool("aaa"); c {
+			params.command = "aaa"
+		}
+		if c, _ := arguments.Bool("sla"); c {
+			params.command = "sla"
+		}
+		if c, _ := arguments.Bool("dhcp"); c {
+			params.command = "dhcp"
+		}
+		if c, _ := arguments.Bool("acl"); c {
+			params.command = "acl"
+		}
+		if c, _ := arguments.Bool("modules"); c {
+			params.command = "modules"
+		}
+		if c, _ := arguments.Bool("inspect"); c {
+			params.command = "inspect"
+		}
+		if c, _ := arguments.Bool("traffic"); c {
+			params.command = "traffic"
+		}
+		if c, _ := arguments.Bool("multi"); c {
+			params.command = "multi"
+		}
 
 		params.version, _ = arguments.Bool("--version")
 		params.port, _ = arguments.Int("--port")
@@ -103,15 +320,178 @@ Options:
 		}
 		params.critical, _ = arguments.String("--critical")
 		params.warning, _ = arguments.String("--warning")
+		params.stateDir, _ = arguments.String("--state-dir")
+		params.ignore, _ = arguments["--ignore"].([]string)
+		params.accept, _ = arguments.Bool("--accept")
+		params.advisory, _ = arguments.String("--advisory")
+		params.rules, _ = arguments["--rule"].([]string)
+		params.report, _ = arguments.Bool("--report")
+		checks, _ := arguments.String("--checks")
+		for _, check := range strings.Split(checks, ",") {
+			params.checks = append(params.checks, strings.TrimSpace(check))
+		}
+		params.passive, _ = arguments.Bool("--passive")
+		params.icingaHost, _ = arguments.String("--icinga-host")
+		servicePrefix, _ = arguments.String("--service-prefix")
+		serviceMap, _ = arguments.String("--service-map")
+
+		params.agent, _ = arguments.Bool("--ssh-agent")
+		params.knownHosts, _ = arguments.String("--known-hosts")
+		params.hostKey, _ = arguments.String("--host-key")
+		params.tofu, _ = arguments.Bool("--tofu")
+		params.connectTimeout, _ = arguments.Int("--connect-timeout")
+		params.commandTimeout, _ = arguments.Int("--command-timeout")
+		params.timeout, _ = arguments.Int("--timeout")
+		params.retries, _ = arguments.Int("--retries")
+		params.retryDelay, _ = arguments.Int("--retry-delay")
+		policy, _ = arguments.String("--error-policy")
+		jump, _ = arguments.String("--jump")
+		jumpHostKey, _ = arguments.String("--jump-host-key")
+
+		params.transport, _ = arguments.String("--transport")
+		params.snmp.Version, _ = arguments.String("--snmp-version")
+		params.snmp.Port, _ = arguments.Int("--snmp-port")
+		params.snmp.Community, _ = arguments.String("--community")
+		params.snmp.Username, _ = arguments.String("--snmp-user")
+		params.snmp.AuthProtocol, _ = arguments.String("--auth-protocol")
+		params.snmp.AuthPassword, _ = arguments.String("--auth-password")
+		params.snmp.PrivProtocol, _ = arguments.String("--priv-protocol")
+		params.snmp.PrivPassword, _ = arguments.String("--priv-password")
+		snmpTimeout, _ = arguments.Int("--snmp-timeout")
+		params.snmp.Retries, _ = arguments.Int("--snmp-retries")
+
+		// Docopt doesn't check option values, SSH commands without username match SNMP usage
+		if params.transport != "snmp" && params.username == "" {
+			fmt.Printf("%s: Error parsing command line arguments: username required with %s transport", ict.UnkMsg, params.transport)
+			os.Exit(ict.UnkExit)
+		}
+
+		// Passwords are taken from option, secret file, credentials file or environment variable
+		params.enable, _ = arguments.String("--enable")
+		if file, _ := arguments.String("--password-file"); file != "" && params.password == "" {
+			params.password, err = readSecret(file)
+			exitOnSecretError(err)
+		}
+		if file, _ := arguments.String("--enable-file"); file != "" && params.enable == "" {
+			params.enable, err = readSecret(file)
+			exitOnSecretError(err)
+		}
+		if credentialsFile, _ = arguments.String("--credentials"); credentialsFile != "" {
+			credentials, err := lookupCredentials(credentialsFile, params.host, params.username)
+			exitOnSecretError(err)
+			if credentials != nil && params.password == "" {
+				params.password = credentials.Password
+			}
+			if credentials != nil && params.enable == "" {
+				params.enable = credentials.Enable
+			}
+			if credentials != nil {
+				credentialsIdentity = credentials.Identity
+			}
+		}
+		if params.password == "" {
+			params.password = os.Getenv("CHECK_CISCOASA_PASSWORD")
+		}
+		if params.enable == "" {
+			params.enable = os.Getenv("CHECK_CISCOASA_ENABLE")
+		}
+	}
+
+	if params.errorPolicy, err = parseErrorPolicy(policy); err != nil {
+		fmt.Printf("%s: Error parsing command line arguments: %v", ict.UnkMsg, err)
+		os.Exit(ict.UnkExit)
+	}
+
+	if params.transport != "ssh" && params.transport != "snmp" {
+		fmt.Printf("%s: Error parsing command line arguments: unknown transport %s", ict.UnkMsg, params.transport)
+		os.Exit(ict.UnkExit)
+	}
+	params.snmp.Timeout = time.Duration(snmpTimeout) * time.Second
+
+	// Passive results are submitted for the Icinga host and services of the checks
+	if params.icingaHost == "" {
+		params.icingaHost = params.host
+	}
+	if params.services, err = serviceNames(params.checks, servicePrefix, serviceMap); err != nil {
+		fmt.Printf("%s: Error parsing command line arguments: %v", ict.UnkMsg, err)
+		os.Exit(ict.UnkExit)
+	}
+	if params.snmp.Community == "" {
+		params.snmp.Community = os.Getenv("CHECK_CISCOASA_COMMUNITY")
+	}
+	if params.snmp.Community == "" {
+		params.snmp.Community = "public"
+	}
+
+	// Passwords and keys of jump hosts are taken from credentials file
+	if params.jumps, err = parseJumps(jump, params.username, params.identity); err != nil {
+		fmt.Printf("%s: Error parsing command line arguments: %v", ict.UnkMsg, err)
+		os.Exit(ict.UnkExit)
+	}
+	if err = pinJumpHostKeys(params.jumps, jumpHostKey); err != nil {
+		fmt.Printf("%s: Error parsing command line arguments: %v", ict.UnkMsg, err)
+		os.Exit(ict.UnkExit)
+	}
+	for i, j := range params.jumps {
+		if credentialsFile == "" {
+			break
+		}
+		credentials, err := lookupCredentials(credentialsFile, j.Host, j.Username)
+		exitOnSecretError(err)
+		if credentials != nil {
+			params.jumps[i].Password = credentials.Password
+			if credentials.Identity != "" {
+				params.jumps[i].Identity = credentials.Identity
+			}
+		}
+	}
+
+	// Key of the credentials file is used for the ASA when --identity is not given, jump hosts keep --identity
+	if credentialsIdentity != "" && params.identity == "~/.ssh/id_rsa" {
+		params.identity = credentialsIdentity
+	}
+}
+
+// exitOnSecretError exit with unknown status if a secret can't be read
+func exitOnSecretError(err error) {
+	if err != nil {
+		fmt.Printf("%s: Error => %s", ict.UnkMsg, err)
+		os.Exit(ict.UnkExit)
 	}
 }
 
+// exitError print the error returned by method and exit with the state of its kind in error policy,
+// by default failures of the monitoring side (authentication, timeout...) are unknown as the state of
+// the ASA was not checked
+func exitError(method string, err error) {
+	kind := errorKind(err)
+	exit := params.errorPolicy[kind]
+	if kind == "other" {
+		fmt.Printf("%s: Error %s => %s", exitMessage(exit), method, err)
+	} else {
+		fmt.Printf("%s: %s", exitMessage(exit), err)
+	}
+	os.Exit(exit)
+}
+
 func main() {
 	var err error
 	var icinga ict.Icinga
 	var asa *CiscoASA
 
 	asa = NewCiscoASA(params.host)
+	asa.Enable = params.enable
+	asa.Agent = params.agent
+	asa.KnownHosts = params.knownHosts
+	asa.HostKey = params.hostKey
+	asa.TOFU = params.tofu
+	asa.ConnectTimeout = time.Duration(params.connectTimeout) * time.Second
+	asa.CommandTimeout = time.Duration(params.commandTimeout) * time.Second
+	asa.Timeout = time.Duration(params.timeout) * time.Second
+	asa.Retries = params.retries
+	asa.RetryDelay = time.Duration(params.retryDelay) * time.Second
+	asa.Jumps = params.jumps
+	asa.SNMP = params.snmp
 
 	// We return version of program and exit with Ok status
 	if params.version {
@@ -122,29 +502,186 @@ func main() {
 	// Check command arguments and calling method
 	switch params.command {
 	case "status":
+		if params.transport == "snmp" {
+			icinga, err = asa.CheckStatusSNMP(params.host, params.critical, params.warning)
+			if err != nil {
+				exitError("CheckStatusSNMP", err)
+			}
+			fmt.Println(icinga)
+			os.Exit(icinga.Exit)
+		}
 		icinga, err = asa.CheckStatus(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
 		if err != nil {
-			fmt.Printf("%s: Error CheckStatus => %s", ict.CriMsg, err)
-			os.Exit(ict.CriExit)
+			exitError("CheckStatus", err)
 		}
 		fmt.Println(icinga)
 		os.Exit(icinga.Exit)
 	case "vpnusers":
+		if params.transport == "snmp" {
+			icinga, err = asa.CheckVPNUsersSNMP(params.host, params.critical, params.warning)
+			if err != nil {
+				exitError("CheckVPNUsersSNMP", err)
+			}
+
+			fmt.Println(icinga)
+			os.Exit(icinga.Exit)
+		}
 		icinga, err = asa.CheckVPNUsers(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
 		if err != nil {
-			fmt.Printf("%s: Error CheckVPNUsers => %s", ict.CriMsg, err)
-			os.Exit(ict.CriExit)
+			exitError("CheckVPNUsers", err)
 		}
 
 		fmt.Println(icinga)
 		os.Exit(icinga.Exit)
 	case "failover":
+		if params.transport == "snmp" {
+			icinga, err = asa.CheckFailoverSNMP(params.host, params.critical, params.warning)
+			if err != nil {
+				exitError("CheckFailoverSNMP", err)
+			}
+
+			fmt.Println(icinga)
+			os.Exit(icinga.Exit)
+		}
 		icinga, err = asa.CheckFailover(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
 		if err != nil {
-			fmt.Printf("%s: Error CheckFailover => %s", ict.CriMsg, err)
-			os.Exit(ict.CriExit)
+			exitError("CheckFailover", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "threats":
+		icinga, err = asa.CheckThreats(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+		if err != nil {
+			exitError("CheckThreats", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "ntp":
+		icinga, err = asa.CheckNTP(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+		if err != nil {
+			exitError("CheckNTP", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "config":
+		icinga, err = asa.CheckConfig(params.host, params.username, params.password, params.identity, params.port, params.stateDir, params.ignore, params.accept)
+		if err != nil {
+			exitError("CheckConfig", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "unsaved":
+		icinga, err = asa.CheckUnsaved(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+		if err != nil {
+			exitError("CheckUnsaved", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "version":
+		icinga, err = asa.CheckVersion(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.advisory)
+		if err != nil {
+			exitError("CheckVersion", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "uptime":
+		icinga, err = asa.CheckUptime(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir, params.accept)
+		if err != nil {
+			exitError("CheckUptime", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "disk":
+		icinga, err = asa.CheckDisk(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+		if err != nil {
+			exitError("CheckDisk", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "logging":
+		icinga, err = asa.CheckLogging(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+		if err != nil {
+			exitError("CheckLogging", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "aaa":
+		icinga, err = asa.CheckAAA(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+		if err != nil {
+			exitError("CheckAAA", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "sla":
+		icinga, err = asa.CheckSLA(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+		if err != nil {
+			exitError("CheckSLA", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "dhcp":
+		icinga, err = asa.CheckDHCP(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+		if err != nil {
+			exitError("CheckDHCP", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "acl":
+		icinga, err = asa.CheckACL(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir, params.rules, params.report, params.accept)
+		if err != nil {
+			exitError("CheckACL", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "modules":
+		icinga, err = asa.CheckModules(params.host, params.username, params.password, params.identity, params.port)
+		if err != nil {
+			exitError("CheckModules", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "inspect":
+		icinga, err = asa.CheckInspect(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+		if err != nil {
+			exitError("CheckInspect", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "traffic":
+		icinga, err = asa.CheckTraffic(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+		if err != nil {
+			exitError("CheckTraffic", err)
+		}
+
+		fmt.Println(icinga)
+		os.Exit(icinga.Exit)
+	case "multi":
+		results, err := asa.CheckMulti(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir, params.advisory, params.checks)
+		if err != nil {
+			exitError("CheckMulti", err)
 		}
 
+		// Each check result is printed as external command for Icinga passive services
+		icinga = CombineResults(results)
+		if params.passive {
+			fmt.Print(PassiveResults(params.icingaHost, params.services, results, time.Now()))
+			os.Exit(icinga.Exit)
+		}
 		fmt.Println(icinga)
 		os.Exit(icinga.Exit)
 	default:
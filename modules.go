@@ -0,0 +1,187 @@
+// This file content implementation of methods to check CISCO ASA service modules (SFR/FirePOWER, IPS)
+// and the service policy redirecting traffic to them
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckModules check Cisco ASA service modules status
+func (asa *CiscoASA) CheckModules(host string, username string, password string, identity string, port int) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show module\n", "show module sfr details\n", "show service-policy sfr\n"})
+	if err != nil {
+		// SFR commands are optional, modules status is evaluated if they timed out
+		return partialResult(stdout, err, []string{"show module"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseModules(r)
+		})
+	}
+
+	return asa.ParseModules(stdout)
+}
+
+// ParseModules parse show module tables, SFR details and SFR service policy and return Icinga result,
+// a module Down, Unresponsive or in recovery is critical as traffic is no more inspected
+func (asa *CiscoASA) ParseModules(response string) (ict.Icinga, error) {
+
+	var reHeader = regexp.MustCompile(`(?i)^Mod\s+(?P<columns>.+?)\s*$`)
+	var reColumn = regexp.MustCompile(`\S+(?: \S+)*`)
+	var reDashes = regexp.MustCompile(`^-+(\s+-+)*\s*$`)
+	var reSpan = regexp.MustCompile(`-+`)
+	var reCritical = regexp.MustCompile(`(?i)(down|unresponsive|recover)`)
+	var reNotInstalled = regexp.MustCompile(`(?i)^(no image present|not present|unknown)`)
+	var reDescription = regexp.MustCompile(`(?mi)^App\. Status Desc:\s*(?P<desc>.+?)\s*$`)
+	var rePolicy = regexp.MustCompile(`(?mi)^\s*SFR: card status (?P<status>.+?), mode (?P<mode>.+?)\s*$`)
+	var rePackets = regexp.MustCompile(`(?mi)^\s*packet input (?P<input>\d+), packet output (?P<output>\d+), drop (?P<drop>\d+), reset-drop (?P<reset>\d+)\s*$`)
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	commands := []string{"show module", "show module sfr details", "show service-policy sfr"}
+	if err := checkOutput(response, commands); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	//
+	// Parsing returned data
+	//
+	// show module print several fixed width tables, columns are delimited by the dashes line below headers
+	modules := make(map[string]map[string]string)
+	var order []string
+	var columns []string
+	var spans [][]int
+	for _, line := range commandOutput(response, "show module") {
+		line = strings.TrimRight(line, " ")
+		switch {
+		case line == "":
+			columns, spans = nil, nil
+		case reHeader.MatchString(line) && spans == nil:
+			// Application table has also a Status column
+			columns = []string{"mod"}
+			prefix := ""
+			if strings.Contains(strings.ToLower(line), "ssm application name") {
+				prefix = "app "
+			}
+			for _, column := range reColumn.FindAllString(line, -1)[1:] {
+				column = strings.ToLower(column)
+				if column == "status" {
+					column = prefix + column
+				}
+				columns = append(columns, column)
+			}
+		case reDashes.MatchString(line) && columns != nil:
+			spans = reSpan.FindAllStringIndex(line, -1)
+		case spans != nil:
+			fields := make([]string, len(spans))
+			for i, span := range spans {
+				start, end := span[0], span[1]
+				if i == len(spans)-1 || end > len(line) {
+					end = len(line)
+				}
+				if start < len(line) {
+					fields[i] = strings.TrimSpace(line[start:end])
+				}
+			}
+			module, known := modules[fields[0]]
+			if !known {
+				module = make(map[string]string)
+				modules[fields[0]] = module
+				order = append(order, fields[0])
+			}
+			for i, column := range columns {
+				if i > 0 && i < len(fields) {
+					module[column] = fields[i]
+				}
+			}
+		}
+	}
+	if len(order) == 0 {
+		return ict.Icinga{}, missingError(response, commands[0], "Module table")
+	}
+
+	up, installed := 0, 0
+	for _, name := range order {
+		module := modules[name]
+		// Software modules not licensed or without image are only listed
+		if reNotInstalled.MatchString(module["app status"]) || reNotInstalled.MatchString(module["card type"]) {
+			details += fmt.Sprintf("\n%s: %s not installed (%s)", name, module["card type"], module["app status"])
+			continue
+		}
+		installed++
+
+		status := module["status"]
+		dataPlane := module["data plane status"]
+		appStatus := module["app status"]
+		switch {
+		case reCritical.MatchString(status):
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("Module %s is %s", name, status))
+		case !strings.HasPrefix(strings.ToLower(status), "up"):
+			if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("Module %s is %s", name, status))
+		case reCritical.MatchString(dataPlane):
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("Module %s data plane is %s", name, dataPlane))
+		case appStatus != "" && !strings.EqualFold(appStatus, "up") && !strings.EqualFold(appStatus, "not applicable"):
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("Module %s application %s is %s", name, module["ssm application name"], appStatus))
+		default:
+			up++
+		}
+
+		details += fmt.Sprintf("\n%s: %s, status %s", name, module["card type"], status)
+		if dataPlane != "" {
+			details += fmt.Sprintf(", data plane %s", dataPlane)
+		}
+		if appStatus != "" {
+			details += fmt.Sprintf(", %s %s %s", module["ssm application name"], appStatus, module["ssm application version"])
+		}
+		if name == "sfr" {
+			if m := reDescription.FindStringSubmatch(strings.Join(commandOutput(response, "show module sfr details"), "\n")); m != nil {
+				details += fmt.Sprintf(" (%s)", m[1])
+			}
+		}
+	}
+	metrics += fmt.Sprintf("'Modules up'=%d;;;0;%d ", up, installed)
+
+	// Traffic redirected to a failed SFR module is either not inspected (fail-open) or dropped (fail-close)
+	policy := strings.Join(commandOutput(response, "show service-policy sfr"), "\n")
+	if m := rePolicy.FindStringSubmatch(policy); m != nil {
+		status, mode := m[1], m[2]
+		if !strings.EqualFold(status, "up") {
+			condition = ict.CriExit
+			if strings.Contains(strings.ToLower(mode), "fail-close") {
+				message = appendMessage(message, fmt.Sprintf("SFR card status %s, redirected traffic dropped (%s)", status, mode))
+			} else {
+				message = appendMessage(message, fmt.Sprintf("SFR card status %s, traffic not inspected (%s)", status, mode))
+			}
+		}
+		details += fmt.Sprintf("\nSFR service policy: card status %s, mode %s", status, mode)
+		if p := rePackets.FindStringSubmatch(policy); p != nil {
+			metrics += fmt.Sprintf("'SFR packets input'=%sc 'SFR packets output'=%sc 'SFR drop'=%sc 'SFR reset-drop'=%sc ", p[1], p[2], p[3], p[4])
+		}
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, name := range order {
+			log.Printf("Module %s - %v\n", name, modules[name])
+		}
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("%d service modules Up", up)
+	}
+	return ict.Icinga{Message: message + details, Exit: condition, Metric: metrics}, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	ModulesResponse = `asa# show module
+
+Mod  Card Type                                    Model              Serial No.
+---- -------------------------------------------- ------------------ -----------
+   0 ASA 5525-X with SW, 8 GE Data, 1 GE Mgmt     ASA5525            FCH1234ABCD
+ ips Unknown                                      N/A                FCH1234ABCD
+ sfr FirePOWER Services Software Module           ASA5525            FCH1234ABCD
+
+Mod  MAC Address Range                 Hw Version   Fw Version   Sw Version
+---- --------------------------------- ------------ ------------ ---------------
+   0 d0c2.8267.1234 to d0c2.8267.123d  1.0          2.1(9)8      9.8(4)20
+ ips d0c2.8267.1232 to d0c2.8267.1232  N/A          N/A
+ sfr d0c2.8267.1230 to d0c2.8267.1230  N/A          N/A          6.2.3-83
+
+Mod  SSM Application Name           Status           SSM Application Version
+---- ------------------------------ ---------------- --------------------------
+ ips Unknown                        No Image Present Not Applicable
+ sfr ASA FirePOWER                  Up               6.2.3-83
+
+Mod  Status             Data Plane Status     Compatibility
+---- ------------------ --------------------- -------------
+   0 Up Sys             Not Applicable
+ ips Unresponsive       Not Applicable
+ sfr Up                 Up
+
+asa# show module sfr details
+Getting details from the Service Module, please wait...
+
+Card Type:          FirePOWER Services Software Module
+Model:              ASA5525
+Software version:   6.2.3-83
+App. name:          ASA FirePOWER
+App. Status:        Up
+App. Status Desc:   Normal Operation
+App. version:       6.2.3-83
+Data Plane Status:  Up
+Status:             Up
+asa# show service-policy sfr
+
+Global policy:
+  Service-policy: global_policy
+    Class-map: sfr_class
+      SFR: card status Up, mode fail-open
+        packet input 123456, packet output 123450, drop 6, reset-drop 0
+asa# `
+)
+
+func TestCiscoASA_ParseModules(t *testing.T) {
+	icinga, err := asa.ParseModules(ModulesResponse)
+	if err != nil {
+		t.Fatalf("Error ParseModules: %s", err)
+	}
+	if icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.OkExit, icinga.Exit, icinga)
+	}
+	want := "2 service modules Up\n0: ASA 5525-X with SW, 8 GE Data, 1 GE Mgmt, status Up Sys, data plane Not Applicable\nips: Unknown not installed (No Image Present)\nsfr: FirePOWER Services Software Module, status Up, data plane Up, ASA FirePOWER Up 6.2.3-83 (Normal Operation)\nSFR service policy: card status Up, mode fail-open"
+	if icinga.Message != want {
+		t.Errorf("Error want message\n%s\ngot\n%s", want, icinga.Message)
+	}
+	if icinga.Metric != "'Modules up'=2;;;0;2 'SFR packets input'=123456c 'SFR packets output'=123450c 'SFR drop'=6c 'SFR reset-drop'=0c " {
+		t.Errorf("Error unexpected metrics %s", icinga.Metric)
+	}
+
+	// SFR module in recovery, inspection bypassed
+	recover := strings.Replace(ModulesResponse, " sfr Up                 Up", " sfr Recover            Not Applicable", 1)
+	recover = strings.Replace(recover, "card status Up", "card status Down", 1)
+	icinga, _ = asa.ParseModules(recover)
+	if icinga.Exit != ict.CriExit || !strings.HasPrefix(icinga.Message, "Module sfr is Recover/SFR card status Down, traffic not inspected (fail-open)\n") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Application down
+	down := strings.Replace(ModulesResponse, "ASA FirePOWER                  Up  ", "ASA FirePOWER                  Down", 1)
+	icinga, _ = asa.ParseModules(down)
+	if icinga.Exit != ict.CriExit || !strings.HasPrefix(icinga.Message, "Module sfr application ASA FirePOWER is Down\n") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Unrecognized output is an error with the offending line
+	if _, err = asa.ParseModules("asa# show module\nasa# "); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "Module table not found in show module => empty output") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
+
+func TestCheck_Modules(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckModules(params.host, params.username, params.password, params.identity, params.port)
+	if err != nil {
+		t.Errorf("Error CheckModules: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,319 @@
+// This file content implementation of methods to evaluate several checks from the output
+// of a single SSH session to the CISCO ASA
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// multiCheck is a check evaluated by the multi command, commands are sent once for all checks
+type multiCheck struct {
+	commands []string
+	parse    func(response string) (ict.Icinga, error)
+}
+
+// CheckResult is the result of one check of the multi command
+type CheckResult struct {
+	Name   string
+	Icinga ict.Icinga
+}
+
+// multiChecks return the checks available in the multi command, all checks share the same thresholds
+// (config and acl need their own options and are not available)
+func (asa *CiscoASA) multiChecks(host string, critical string, warning string, stateDir string, advisories *Advisories) map[string]multiCheck {
+	return map[string]multiCheck{
+		"status": {[]string{"show environment", "show cpu", "show mem"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseStatus(r, critical, warning)
+		}},
+		"vpnusers": {[]string{"show uauth | include remote access VPN user"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseVPNUsers(r, critical, warning)
+		}},
+		"failover": {[]string{"show failover"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseFailover(r, critical, warning)
+		}},
+		"threats": {[]string{"show threat-detection rate", "show threat-detection statistics top", "show shun"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseThreats(r, critical, warning)
+		}},
+		"ntp": {[]string{"show ntp status", "show ntp associations", "show running-config clock", "show clock"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseNTP(r, time.Now(), critical, warning), nil
+		}},
+		"unsaved": {[]string{"show checksum", "show startup-config | include Cryptochecksum"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseUnsaved(r, stateFile(stateDir, host, "unsaved.json"), time.Now(), critical, warning)
+		}},
+		"version": {[]string{"show version"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseVersion(r, advisories, time.Now(), critical, warning), nil
+		}},
+		"uptime": {[]string{"show version", "show reload", "show crashinfo"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseUptime(r, stateFile(stateDir, host, "uptime.json"), critical, warning, false)
+		}},
+		"disk": {[]string{"dir /recursive all-filesystems"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseDisk(r, critical, warning)
+		}},
+		"logging": {[]string{"show logging setting", "show logging queue"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseLogging(r, stateFile(stateDir, host, "logging.json"), critical, warning)
+		}},
+		"aaa": {[]string{"show aaa-server"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseAAA(r, stateFile(stateDir, host, "aaa.json"), critical, warning)
+		}},
+		"sla": {[]string{"show sla monitor operational-state", "show track"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseSLA(r, stateFile(stateDir, host, "sla.json"), critical, warning)
+		}},
+		"dhcp": {[]string{"show running-config dhcpd", "show dhcpd binding", "show dhcpd statistics"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseDHCP(r, critical, warning)
+		}},
+		"modules": {[]string{"show module", "show module sfr details", "show service-policy sfr"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseModules(r)
+		}},
+		"inspect": {[]string{"show service-policy"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseInspect(r, stateFile(stateDir, host, "inspect.json"), critical, warning)
+		}},
+		"traffic": {[]string{"show interface"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseTraffic(r, critical, warning)
+		}},
+	}
+}
+
+// CheckMulti open a single SSH session, send the commands needed by all checks once and evaluate each check
+func (asa *CiscoASA) CheckMulti(host string, username string, password string, identity string, port int, critical string, warning string, stateDir string, advisory string, checks []string) ([]CheckResult, error) {
+
+	var advisories *Advisories
+
+	if advisory != "" {
+		var err error
+		if advisories, err = loadAdvisories(advisory); err != nil {
+			return nil, err
+		}
+	}
+
+	// Union of the commands of all checks, each command is sent only once
+	available := asa.multiChecks(host, critical, warning, stateDir, advisories)
+	var commands []string
+	sent := make(map[string]bool)
+	for _, name := range checks {
+		check, found := available[name]
+		if !found {
+			return nil, fmt.Errorf("CheckMulti, %w, unknown check %s", errConfig, name)
+		}
+		for _, command := range check.commands {
+			if !sent[command] {
+				sent[command] = true
+				commands = append(commands, command+"\n")
+			}
+		}
+	}
+
+	// On timeout or rejected command the checks whose commands all completed are still evaluated
+	stdout, err := asa.sendCommands(host, username, password, identity, port, commands)
+	if err != nil && (!errors.Is(err, errTimeout) && !errors.Is(err, errUnsupported) || stdout == "") {
+		return nil, err
+	}
+
+	results := asa.ParseMulti(stdout, checks, available)
+	if err != nil {
+		completed := completedCommands(stdout)
+		rejected := rejectedCommands(stdout)
+		for i, name := range checks {
+			for _, command := range available[name].commands {
+				if line, found := rejected[command]; found && !optionalCommands[command] {
+					results[i].Icinga = ict.Icinga{Message: rejectedError(command, line).Error(), Exit: ict.UnkExit}
+					break
+				}
+				if !completed[command] {
+					results[i].Icinga = ict.Icinga{Message: err.Error(), Exit: ict.UnkExit}
+					break
+				}
+			}
+		}
+	}
+	return results, nil
+}
+
+// ParseMulti evaluate each check with the output of its own commands, a check returning an error is unknown
+func (asa *CiscoASA) ParseMulti(response string, checks []string, available map[string]multiCheck) []CheckResult {
+	var results []CheckResult
+
+	for _, name := range checks {
+		check, found := available[name]
+		if !found {
+			results = append(results, CheckResult{name, ict.Icinga{Message: fmt.Sprintf("Unknown check %s", name), Exit: ict.UnkExit}})
+			continue
+		}
+		icinga, err := parseCheck(check, filterOutput(response, check.commands))
+		if err != nil {
+			icinga = ict.Icinga{Message: fmt.Sprintf("Error %s => %s", name, err), Exit: ict.UnkExit}
+		}
+		results = append(results, CheckResult{name, icinga})
+	}
+	return results
+}
+
+// parseCheck evaluate check with response, a panic of the parser is returned as a parse error so other
+// checks of a multi run are still reported
+func parseCheck(check multiCheck, response string) (icinga ict.Icinga, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			icinga, err = ict.Icinga{}, fmt.Errorf("%w, parser failure: %v", errParse, r)
+		}
+	}()
+	return check.parse(response)
+}
+
+// filterOutput keep only the output of commands with their prompt line, so a check never parse the
+// output of commands sent for other checks
+func filterOutput(stdout string, commands []string) string {
+	var rePrompt = regexp.MustCompile(`^[\w\-./()]+[#>]`)
+	var filtered []string
+
+	keep := make(map[string]bool)
+	for _, command := range commands {
+		keep[command] = true
+	}
+
+	found := false
+	for _, line := range strings.Split(strings.Replace(stdout, "\r", "", -1), "\n") {
+		if rePrompt.MatchString(line) {
+			// Prompt ending the output of the last kept command
+			if found {
+				filtered = append(filtered, rePrompt.FindString(line)+" ")
+			}
+			found = keep[strings.TrimSpace(rePrompt.ReplaceAllString(line, ""))]
+		}
+		if found {
+			filtered = append(filtered, line)
+		}
+	}
+	return strings.Join(filtered, "\n")
+}
+
+// completedCommands return the commands whose output is complete, followed by the next prompt
+func completedCommands(stdout string) map[string]bool {
+	var rePrompt = regexp.MustCompile(`^[\w\-./()]+[#>]`)
+	completed := make(map[string]bool)
+
+	last := ""
+	for _, line := range strings.Split(strings.Replace(stdout, "\r", "", -1), "\n") {
+		if rePrompt.MatchString(line) {
+			if last != "" {
+				completed[last] = true
+			}
+			last = strings.TrimSpace(rePrompt.ReplaceAllString(line, ""))
+		}
+	}
+	return completed
+}
+
+// worstExit return the most severe exit code, severity order is OK, WARNING, UNKNOWN then CRITICAL
+func worstExit(a int, b int) int {
+	var severity = map[int]int{ict.OkExit: 0, ict.WarExit: 1, ict.UnkExit: 2, ict.CriExit: 3}
+
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+// partialResult evaluate with parse the output of commands completed before a timeout, only if the required
+// commands completed. The result is at least unknown and name the timeout, the timeout error is returned
+// if the output can't be evaluated
+func partialResult(stdout string, err error, required []string, parse func(response string) (ict.Icinga, error)) (ict.Icinga, error) {
+	if !errors.Is(err, errTimeout) {
+		return ict.Icinga{}, err
+	}
+	completed := completedCommands(stdout)
+	var commands []string
+	for command := range completed {
+		commands = append(commands, command)
+	}
+	for _, command := range required {
+		if !completed[command] {
+			return ict.Icinga{}, err
+		}
+	}
+
+	icinga, parseErr := parse(filterOutput(stdout, commands))
+	if parseErr != nil {
+		return ict.Icinga{}, err
+	}
+	icinga.Exit = worstExit(icinga.Exit, ict.UnkExit)
+	icinga.Message = fmt.Sprintf("Partial result, %s / %s", err, icinga.Message)
+	return icinga, nil
+}
+
+// CombineResults return a single Icinga result, severity order is OK, WARNING, UNKNOWN then CRITICAL
+func CombineResults(results []CheckResult) ict.Icinga {
+	var condition = ict.OkExit
+	var summary []string
+	var details = ""
+	var metrics = ""
+
+	for _, result := range results {
+		condition = worstExit(condition, result.Icinga.Exit)
+		summary = append(summary, fmt.Sprintf("%s %s", result.Name, exitMessage(result.Icinga.Exit)))
+		details += fmt.Sprintf("\n[%s] %s: %s", result.Name, exitMessage(result.Icinga.Exit), result.Icinga.Message)
+		metrics += result.Icinga.Metric
+	}
+	return ict.Icinga{Message: strings.Join(summary, ", ") + details, Exit: condition, Metric: metrics}
+}
+
+// serviceNames return the Icinga service name of each check, mapping is a comma separated list of check=service,
+// checks not in mapping are named with prefix followed by the check name
+func serviceNames(checks []string, prefix string, mapping string) (map[string]string, error) {
+	services := make(map[string]string)
+	for _, check := range checks {
+		services[check] = prefix + check
+	}
+
+	for _, item := range strings.Split(mapping, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		fields := strings.SplitN(item, "=", 2)
+		check := strings.TrimSpace(fields[0])
+		if _, found := services[check]; !found {
+			return nil, fmt.Errorf("serviceNames, check %s of service mapping not in checks", check)
+		}
+		if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+			return nil, fmt.Errorf("serviceNames, missing service name of check %s", check)
+		}
+		services[check] = strings.TrimSpace(fields[1])
+	}
+	return services, nil
+}
+
+// PassiveResults format results as Icinga/Nagios external commands for services of host, the service name
+// is the name of the check in services, the check name if not found
+func PassiveResults(host string, services map[string]string, results []CheckResult, now time.Time) string {
+	var out strings.Builder
+
+	for _, result := range results {
+		service, found := services[result.Name]
+		if !found {
+			service = result.Name
+		}
+		output := strings.Replace(strings.TrimRight(result.Icinga.Message, "\n"), "\n", `\n`, -1)
+		if result.Icinga.Metric != "" {
+			output += "|" + strings.TrimSpace(result.Icinga.Metric)
+		}
+		fmt.Fprintf(&out, "[%d] PROCESS_SERVICE_CHECK_RESULT;%s;%s;%d;%s: %s\n", now.Unix(), host, service, result.Icinga.Exit, exitMessage(result.Icinga.Exit), output)
+	}
+	return out.String()
+}
+
+// exitMessage return the status name of an exit code
+func exitMessage(exit int) string {
+	switch exit {
+	case ict.OkExit:
+		return ict.OkMsg
+	case ict.WarExit:
+		return ict.WarMsg
+	case ict.CriExit:
+		return ict.CriMsg
+	}
+	return ict.UnkMsg
+}
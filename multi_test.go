@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	FailoverResponse = `asa# show failover
+Failover On
+Failover unit Primary
+Failover LAN Interface: folink GigabitEthernet0/7 (up)
+Last Failover at: 10:15:30 CEST Jun 1 2020
+	This host: Primary - Active 
+		Active time: 86400 (sec)
+	Other host: Secondary - Standby Ready 
+		Active time: 0 (sec)
+asa# `
+	VPNUsersResponse = `asa# show uauth | include remote access VPN user
+remote access VPN user 'alice' at 10.10.10.1, authenticated
+remote access VPN user 'bob' at 10.10.10.2, authenticated
+asa# `
+)
+
+func TestCiscoASA_ParseMulti(t *testing.T) {
+	response := strings.TrimSuffix(FailoverResponse, "asa# ") + strings.TrimSuffix(VPNUsersResponse, "asa# ") + DHCPResponse
+	available := asa.multiChecks("asa", `{"users_vpn":1,"dhcp_pool":90}`, `{"dhcp_pool":70}`, "", nil)
+
+	results := asa.ParseMulti(response, []string{"failover", "vpnusers", "dhcp", "bogus"}, available)
+	if len(results) != 4 {
+		t.Fatalf("Error want 4 results got %d", len(results))
+	}
+	for i, exit := range []int{ict.OkExit, ict.CriExit, ict.WarExit, ict.UnkExit} {
+		if results[i].Icinga.Exit != exit {
+			t.Errorf("Error %s want exit %d got %d (%s)", results[i].Name, exit, results[i].Icinga.Exit, results[i].Icinga)
+		}
+	}
+
+	icinga := CombineResults(results)
+	if icinga.Exit != ict.CriExit || !strings.HasPrefix(icinga.Message, "failover OK, vpnusers CRITICAL, dhcp WARNING, bogus UNKNOWN\n[failover] OK: Last failover -> 01 June 2020 10:15:30") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// A parser failure is unknown and doesn't stop other checks
+	available["panic"] = multiCheck{[]string{"show failover"}, func(r string) (ict.Icinga, error) {
+		var lines []string
+		return ict.Icinga{Message: lines[1]}, nil
+	}}
+	isolated := asa.ParseMulti(response, []string{"panic", "vpnusers"}, available)
+	if len(isolated) != 2 || isolated[0].Icinga.Exit != ict.UnkExit || !strings.Contains(isolated[0].Icinga.Message, "unparseable output, parser failure") || isolated[1].Icinga.Exit != ict.CriExit {
+		t.Errorf("Error unexpected results %v", isolated)
+	}
+
+	passive := PassiveResults("asa", nil, results[:2], time.Unix(1591000000, 0))
+	want := "[1591000000] PROCESS_SERVICE_CHECK_RESULT;asa;failover;0;OK: Last failover -> 01 June 2020 10:15:30 / Primary host is Active, Secondary host is Standby Ready|'Active Time'=86400s\n" +
+		"[1591000000] PROCESS_SERVICE_CHECK_RESULT;asa;vpnusers;2;CRITICAL: 2 VPN remote connected users > 1|'Active users'=2\n"
+	if passive != want {
+		t.Errorf("Error want\n%s\ngot\n%s", want, passive)
+	}
+
+	// Icinga host and service names differ from ASA address and check names
+	services, err := serviceNames([]string{"failover", "vpnusers"}, "ASA ", "vpnusers=VPN users")
+	if err != nil {
+		t.Fatalf("Error serviceNames: %s", err)
+	}
+	passive = PassiveResults("fw-hq", services, results[:2], time.Unix(1591000000, 0))
+	if !strings.HasPrefix(passive, "[1591000000] PROCESS_SERVICE_CHECK_RESULT;fw-hq;ASA failover;0;") || !strings.Contains(passive, "\n[1591000000] PROCESS_SERVICE_CHECK_RESULT;fw-hq;VPN users;2;") {
+		t.Errorf("Error unexpected passive results\n%s", passive)
+	}
+	for _, mapping := range []string{"dhcp=DHCP", "vpnusers", "vpnusers= "} {
+		if _, err = serviceNames([]string{"failover", "vpnusers"}, "", mapping); err == nil {
+			t.Errorf("Error invalid service mapping %s accepted", mapping)
+		}
+	}
+}
+
+func TestCiscoASA_CheckMulti(t *testing.T) {
+	fake := &fakeASA{password: "secret", privileged: true, responses: map[string]string{
+		"show failover": strings.TrimPrefix(strings.TrimSuffix(FailoverResponse, "asa# "), "asa# show failover\n"),
+		"show uauth | include remote access VPN user": strings.TrimPrefix(strings.TrimSuffix(VPNUsersResponse, "asa# "), "asa# show uauth | include remote access VPN user\n"),
+	}}
+	port := fake.start(t)
+
+	results, err := asa.CheckMulti("127.0.0.1", "icinga", "secret", "", port, `{}`, `{}`, "", "", []string{"failover", "vpnusers", "failover"})
+	if err != nil {
+		t.Fatalf("Error CheckMulti: %s", err)
+	}
+	if len(results) != 3 || results[0].Icinga.Exit != ict.OkExit || results[1].Icinga.Message != "2 VPN remote connected users" {
+		t.Errorf("Error unexpected results %v", results)
+	}
+
+	// Commands needed by several checks are sent once
+	fake.mu.Lock()
+	if strings.Join(fake.received, ",") != "terminal pager 0,show failover,show uauth | include remote access VPN user" {
+		t.Errorf("Error unexpected commands %v", fake.received)
+	}
+	fake.mu.Unlock()
+
+	if _, err = asa.CheckMulti("127.0.0.1", "icinga", "secret", "", port, `{}`, `{}`, "", "", []string{"bogus"}); !errors.Is(err, errConfig) {
+		t.Errorf("Error want %s got %v", errConfig, err)
+	}
+}
+
+func TestCiscoASA_CheckMultiTimeout(t *testing.T) {
+	fake := &fakeASA{password: "secret", privileged: true, stalled: "show uauth | include remote access VPN user", responses: map[string]string{
+		"show failover": strings.TrimPrefix(strings.TrimSuffix(FailoverResponse, "asa# "), "asa# show failover\n"),
+	}}
+	port := fake.start(t)
+
+	// Failover output is complete and still evaluated, vpnusers timed out
+	device := NewCiscoASA("asa")
+	device.CommandTimeout = 200 * time.Millisecond
+	results, err := device.CheckMulti("127.0.0.1", "icinga", "secret", "", port, `{}`, `{}`, "", "", []string{"failover", "vpnusers"})
+	if err != nil {
+		t.Fatalf("Error CheckMulti: %s", err)
+	}
+	if results[0].Icinga.Exit != ict.OkExit {
+		t.Errorf("Error want failover OK got %v", results[0].Icinga)
+	}
+	if results[1].Icinga.Exit != ict.UnkExit || !strings.Contains(results[1].Icinga.Message, "timeout waiting output of show uauth") {
+		t.Errorf("Error want vpnusers timeout got %v", results[1].Icinga)
+	}
+
+	// Command rejected, only the checks using it are unknown
+	fake = &fakeASA{password: "secret", privileged: true, responses: map[string]string{
+		"show failover": strings.TrimPrefix(strings.TrimSuffix(FailoverResponse, "asa# "), "asa# show failover\n"),
+		"show uauth | include remote access VPN user": "Command authorization failed\n",
+	}}
+	port = fake.start(t)
+	if results, err = device.CheckMulti("127.0.0.1", "icinga", "secret", "", port, `{}`, `{}`, "", "", []string{"failover", "vpnusers"}); err != nil {
+		t.Fatalf("Error CheckMulti: %s", err)
+	}
+	if results[0].Icinga.Exit != ict.OkExit {
+		t.Errorf("Error want failover OK got %v", results[0].Icinga)
+	}
+	if results[1].Icinga.Exit != ict.UnkExit || !strings.HasSuffix(results[1].Icinga.Message, "=> Command authorization failed") {
+		t.Errorf("Error want vpnusers rejected got %v", results[1].Icinga)
+	}
+}
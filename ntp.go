@@ -0,0 +1,209 @@
+// This file content implementation of methods to check CISCO ASA NTP synchronization
+// and clock drift
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// clockZones return the offset in seconds of the time zones of ASA clock configuration (clock timezone and
+// clock summer-time), summer time offset is one hour unless set at the end of the command
+func clockZones(response string) map[string]int {
+	var reTimezone = regexp.MustCompile(`(?mi)^\s*clock timezone\s+(?P<name>\S+)\s+(?P<hours>-?\d+)(?:\s+(?P<minutes>\d+))?\s*$`)
+	var reSummer = regexp.MustCompile(`(?mi)^\s*clock summer-time\s+(?P<name>\S+)\s+(?P<rule>.*?)\s*$`)
+	var reSummerOffset = regexp.MustCompile(`\s(\d+)$`)
+
+	zones := map[string]int{"UTC": 0, "GMT": 0}
+	standard := 0
+	if tz := reTimezone.FindStringSubmatch(response); tz != nil {
+		hours, _ := strconv.Atoi(tz[2])
+		minutes, _ := strconv.Atoi(tz[3])
+		if hours < 0 {
+			minutes = -minutes
+		}
+		standard = hours*3600 + minutes*60
+		zones[tz[1]] = standard
+	}
+	if summer := reSummer.FindStringSubmatch(response); summer != nil {
+		offset := 60
+		if m := reSummerOffset.FindStringSubmatch(" " + summer[2]); m != nil {
+			offset, _ = strconv.Atoi(m[1])
+		}
+		zones[summer[1]] = standard + offset*60
+	}
+	return zones
+}
+
+// asaClock return the time of show clock output, the time zone offset is taken from zones or from the local
+// time zone if it has the same name, an errParse is returned if the offset of the time zone is unknown
+func asaClock(clock string, zones map[string]int) (time.Time, error) {
+	const layout = "15:04:05.000 MST Mon Jan 2 2006"
+	clock = strings.Join(strings.Fields(clock), " ")
+
+	t, err := time.ParseInLocation(layout, clock, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	name, _ := t.Zone()
+	if offset, found := zones[name]; found {
+		return time.ParseInLocation(layout, clock, time.FixedZone(name, offset))
+	}
+	// Unknown abbreviations are parsed with a zero offset in a fabricated location
+	if t.Location() != time.Local {
+		return time.Time{}, fmt.Errorf("%w, offset of ASA time zone %s unknown (show running-config clock)", errParse, name)
+	}
+	return t, nil
+}
+
+// CheckNTP check Cisco ASA NTP synchronization state, associations and drift between ASA clock
+// and monitoring host clock
+func (asa *CiscoASA) CheckNTP(host string, username string, password string, identity string, port int, critical string, warning string) (ict.Icinga, error) {
+
+	// show clock is sent last to be as close as possible to the local time reference, clock configuration
+	// give the offset of the ASA time zone
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show ntp status\n", "show ntp associations\n", "show running-config clock\n", "show clock\n"})
+	if err != nil {
+		// Synchronization is evaluated if clock commands timed out
+		return partialResult(stdout, err, []string{"show ntp status", "show ntp associations"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseNTP(r, time.Now(), critical, warning), nil
+		})
+	}
+
+	return asa.ParseNTP(stdout, time.Now(), critical, warning), nil
+}
+
+// ParseNTP parse the output of NTP and clock commands and return Icinga result depending critical
+// and warning thresholds, ASA clock is compared to now
+func (asa *CiscoASA) ParseNTP(response string, now time.Time, critical string, warning string) ict.Icinga {
+
+	var reStatus = regexp.MustCompile(`(?mi)^\s*Clock is (?P<state>\w+), stratum (?P<stratum>\d+)(?:, reference is (?P<reference>\S+))?.*$`)
+	var reOffset = regexp.MustCompile(`(?mi)^\s*clock offset is (?P<offset>-?[\d.]+) msec.*$`)
+	var reAssociation = regexp.MustCompile(`(?mi)^\s*(?P<flags>[*#+\-x ]?~?)(?P<address>\d{1,3}(?:\.\d{1,3}){3}|[\w.:\-]+)\s+(?P<reference>\S+)\s+(?P<stratum>\d+)\s+(?P<when>\S+)\s+(?P<poll>\d+)\s+(?P<reach>\d+)\s+(?P<delay>-?[\d.]+)\s+(?P<offset>-?[\d.]+)\s+(?P<dispersion>-?[\d.]+)\s*$`)
+	var reClock = regexp.MustCompile(`(?mi)^\s*[*.]?(?P<time>\d{2}:\d{2}:\d{2}\.\d{3}\s+[A-Z]{1,5}\s+\w{3}\s+\w{3}\s+\d{1,2}\s+\d{4})\s*$`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for NTP associations
+	keys := reAssociation.SubexpNames()[1:]
+	var associations []map[string]string
+	for _, s := range reAssociation.FindAllStringSubmatch(response, -1) {
+		association := make(map[string]string)
+		for i, v := range s[1:] {
+			association[keys[i]] = v
+		}
+		associations = append(associations, association)
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	// Checking if clock is synchronized, if no information are returned exiting with Unknown status
+	status := reStatus.FindStringSubmatch(response)
+	if status == nil {
+		return ict.Icinga{Message: "NTP status not found", Exit: ict.UnkExit}
+	}
+	stratum, _ := strconv.Atoi(status[2])
+	if strings.ToLower(status[1]) != "synchronized" {
+		condition = ict.CriExit
+		message = appendMessage(message, fmt.Sprintf("Clock is %s (stratum %d)", status[1], stratum))
+	} else {
+		message = appendMessage(message, fmt.Sprintf("Clock is synchronized to %s (stratum %d)", status[3], stratum))
+	}
+	metrics += fmt.Sprintf("'Stratum'=%d ", stratum)
+
+	// Checking offset of the synchronized clock
+	if offset := reOffset.FindStringSubmatch(response); offset != nil {
+		ms, _ := strconv.ParseFloat(offset[1], 64)
+		if errCritical == nil && criticalTH.NTPOffset > 0 && math.Abs(ms) > criticalTH.NTPOffset {
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("NTP offset %.3f ms > %.3f ms", ms, criticalTH.NTPOffset))
+		} else if errWarning == nil && warningTH.NTPOffset > 0 && math.Abs(ms) > warningTH.NTPOffset {
+			if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("NTP offset %.3f ms > %.3f ms", ms, warningTH.NTPOffset))
+		}
+		metrics += fmt.Sprintf("'Offset'=%.3fms ", ms)
+	}
+
+	// Comparing ASA clock with local clock
+	if clock := reClock.FindStringSubmatch(response); clock != nil {
+		asaTime, err := asaClock(clock[1], clockZones(response))
+		if errors.Is(err, errParse) {
+			if condition == ict.OkExit {
+				condition = ict.UnkExit
+			}
+			message = appendMessage(message, err.Error())
+		} else if err == nil {
+			drift := math.Abs(now.Sub(asaTime).Seconds())
+			if errCritical == nil && criticalTH.ClockDrift > 0 && drift > float64(criticalTH.ClockDrift) {
+				condition = ict.CriExit
+				message = appendMessage(message, fmt.Sprintf("Clock drift %.0f s > %d s", drift, criticalTH.ClockDrift))
+			} else if errWarning == nil && warningTH.ClockDrift > 0 && drift > float64(warningTH.ClockDrift) {
+				if condition < ict.WarExit {
+					condition = ict.WarExit
+				}
+				message = appendMessage(message, fmt.Sprintf("Clock drift %.0f s > %d s", drift, warningTH.ClockDrift))
+			}
+			metrics += fmt.Sprintf("'Clock drift'=%.3fs ", drift)
+		} else {
+			if condition == ict.OkExit {
+				condition = ict.UnkExit
+			}
+			message = appendMessage(message, fmt.Sprintf("Invalid ASA clock %s", strings.TrimSpace(clock[1])))
+		}
+	}
+
+	// Listing NTP servers in long output
+	for _, association := range associations {
+		offset, _ := strconv.ParseFloat(association["offset"], 64)
+		reach, _ := strconv.ParseInt(association["reach"], 8, 64)
+		details += fmt.Sprintf("\n%s%s - stratum %s, offset %.3f ms, reach %s", strings.TrimSpace(strings.Replace(association["flags"], "~", "", -1)), association["address"], association["stratum"], offset, association["reach"])
+		metrics += fmt.Sprintf("'%s offset'=%.3fms ", association["address"], offset)
+		metrics += fmt.Sprintf("'%s reach'=%d;;;0;8 ", association["address"], countBits(reach))
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		log.Printf("Clock is %s, stratum %s, reference %s\n", status[1], status[2], status[3])
+		for _, association := range associations {
+			log.Printf("%s - ref %s - st %s - reach %s - delay %s - offset %s - disp %s\n", association["address"], association["reference"], association["stratum"], association["reach"], association["delay"], association["offset"], association["dispersion"])
+		}
+	}
+
+	if details != "" {
+		message += "\nNTP servers:" + details
+	}
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}
+}
+
+// countBits return the number of bits set in the NTP reach register
+func countBits(value int64) int {
+	count := 0
+	for ; value > 0; value >>= 1 {
+		count += int(value & 1)
+	}
+	return count
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	NTPSyncResponse = `asa# show ntp status
+Clock is synchronized, stratum 3, reference is 10.1.1.1
+nominal freq is 99.9984 Hz, actual freq is 100.0000 Hz, precision is 2**6
+reference time is dae6f8c2.1a2b3c4d (14:05:22.102 UTC Tue Mar 15 2016)
+clock offset is 0.5140 msec, root delay is 1.35 msec
+root dispersion is 15.91 msec, peer dispersion is 0.66 msec
+asa# show ntp associations
+
+      address         ref clock     st  when  poll reach  delay  offset    disp
+*~10.1.1.1          192.168.1.1      2    48    64  377     0.7    0.51     0.7
++~10.1.1.2          192.168.1.1      2    40    64  377     1.1   -0.12     1.0
+ ~10.1.1.3          .INIT.          16     -    64    0     0.0    0.00 16000.
+ * master (synced), # master (unsynced), + selected, - candidate, ~ configured
+asa# show clock
+14:06:10.215 UTC Tue Mar 15 2016
+asa# `
+
+	NTPUnsyncResponse = `asa# show ntp status
+Clock is unsynchronized, stratum 16, no reference clock
+nominal freq is 99.9984 Hz, actual freq is 99.9984 Hz, precision is 2**6
+reference time is 00000000.00000000 (00:00:00.000 UTC Mon Jan 1 1900)
+clock offset is 0.0000 msec, root delay is 0.00 msec
+asa# show clock
+14:06:10.215 UTC Tue Mar 15 2016
+asa# `
+
+	NTPServersCount = 3
+)
+
+func TestCiscoASA_ParseNTP(t *testing.T) {
+	now := time.Date(2016, time.March, 15, 14, 6, 40, 0, time.UTC)
+
+	icinga := asa.ParseNTP(NTPSyncResponse, now, `{"clock_drift":60}`, `{"ntp_offset":100,"clock_drift":10}`)
+	if icinga.Exit != ict.WarExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+	if !strings.HasPrefix(icinga.Message, "Clock is synchronized to 10.1.1.1 (stratum 3)/Clock drift 30 s > 10 s") {
+		t.Errorf("Error unexpected message: %s", icinga.Message)
+	}
+	if n := strings.Count(icinga.Message, "\n") - 1; n != NTPServersCount {
+		t.Errorf("Error want %d NTP servers got %d", NTPServersCount, n)
+	}
+	if !strings.Contains(icinga.Metric, "'10.1.1.1 reach'=8;;;0;8 ") || !strings.Contains(icinga.Metric, "'10.1.1.2 offset'=-0.120ms ") {
+		t.Errorf("Error unexpected metrics: %s", icinga.Metric)
+	}
+
+	icinga = asa.ParseNTP(NTPUnsyncResponse, now, `{}`, `{}`)
+	if icinga.Exit != ict.CriExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// ASA clock in summer time, offset taken from clock configuration
+	response := strings.Replace(NTPSyncResponse, "asa# show clock\n14:06:10.215 UTC", "asa# show running-config clock\nclock timezone CET 1\nclock summer-time CEST recurring last Sun Mar 2:00 last Sun Oct 3:00\nasa# show clock\n16:06:10.215 CEST", 1)
+	icinga = asa.ParseNTP(response, now, `{"clock_drift":60}`, `{"clock_drift":40}`)
+	if icinga.Exit != ict.OkExit || !strings.Contains(icinga.Metric, "'Clock drift'=29.785s ") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.OkExit, icinga.Exit, icinga)
+	}
+
+	// Time zone without configuration is unknown instead of a wrong drift
+	response = strings.Replace(NTPSyncResponse, "14:06:10.215 UTC", "16:06:10.215 CEST", 1)
+	icinga = asa.ParseNTP(response, now, `{"clock_drift":60}`, `{}`)
+	if icinga.Exit != ict.UnkExit || !strings.Contains(icinga.Message, "offset of ASA time zone CEST unknown") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.UnkExit, icinga.Exit, icinga)
+	}
+
+	icinga = asa.ParseNTP("asa# ", now, `{}`, `{}`)
+	if icinga.Exit != ict.UnkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.UnkExit, icinga.Exit, icinga)
+	}
+}
+
+func TestClockZones(t *testing.T) {
+	zones := clockZones("clock timezone NST -3 30\nclock summer-time NDT date 1 April 2020 2:00 31 October 2020 2:00 30\n")
+	if zones["NST"] != -12600 || zones["NDT"] != -10800 || zones["UTC"] != 0 {
+		t.Errorf("Error unexpected zones %v", zones)
+	}
+}
+
+func TestCheck_NTP(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckNTP(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+	if err != nil {
+		t.Errorf("Error CheckNTP: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,75 @@
+// This file content the retry of SSH connections failing for transient reasons, ASA limit the number of
+// concurrent SSH sessions and reject new connections while an administrator is connected
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"regexp"
+	"time"
+)
+
+// maxRetryDelay is the longest delay between two connection attempts before jitter
+const maxRetryDelay = time.Minute
+
+// reTransient match connection failures worth a retry, messages of Windows are included
+var reTransient = regexp.MustCompile(`(?i)connection refused|actively refused|connection reset|forcibly closed`)
+
+// openSessionRetry open the session, retrying transient connection failures with a jittered exponential
+// backoff, no retry is done if the delay would exceed the overall deadline
+func (asa *CiscoASA) openSessionRetry(ctx context.Context, host string, username string, password string, identity string, port int) (*cliSession, error) {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for attempt := 1; ; attempt++ {
+		session, err := asa.openSession(ctx, host, username, password, identity, port)
+		if err == nil || !retryable(err) || attempt > asa.Retries {
+			if err != nil && attempt > 1 {
+				err = fmt.Errorf("%w (%d attempts)", err, attempt)
+			}
+			return session, err
+		}
+
+		delay := backoff(asa.RetryDelay, attempt, random.Float64)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("%w (%d attempts, no time left to retry)", err, attempt)
+		}
+		if os.Getenv("VERBOSE") == "TRUE" {
+			log.Printf("Attempt %d/%d failed: %s, retrying in %s", attempt, asa.Retries+1, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w (%d attempts)", err, attempt)
+		}
+	}
+}
+
+// retryable return true if the connection was refused or reset, or closed because the maximum number of
+// SSH sessions is reached, other failures (unknown host, unreachable network...) are not transient
+func retryable(err error) bool {
+	if !errors.Is(err, errConnection) {
+		return false
+	}
+	return reTransient.MatchString(err.Error()) || reMaxSessions.MatchString(err.Error())
+}
+
+// backoff return the delay before retry following attempt, base delay is doubled at each attempt up to
+// maxRetryDelay and a random jitter of up to 50% is removed so checks of several services don't retry at
+// the same time
+func backoff(base time.Duration, attempt int, random func() float64) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay > 0 && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay - time.Duration(random()*float64(delay)/2)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		random  float64
+		want    time.Duration
+	}{
+		{1, 0, time.Second},
+		{2, 0, 2 * time.Second},
+		{3, 0, 4 * time.Second},
+		{3, 0.5, 3 * time.Second},
+		{3, 1, 2 * time.Second},
+		{7, 0, maxRetryDelay},
+		{64, 0, maxRetryDelay},
+		{1000, 1, maxRetryDelay / 2},
+	}
+	for _, test := range tests {
+		if delay := backoff(time.Second, test.attempt, func() float64 { return test.random }); delay != test.want {
+			t.Errorf("Error attempt %d random %.1f want %s got %s", test.attempt, test.random, test.want, delay)
+		}
+	}
+
+	// Base delay longer than the maximum
+	if delay := backoff(2*time.Hour, 3, func() float64 { return 0 }); delay != maxRetryDelay {
+		t.Errorf("Error want %s got %s", maxRetryDelay, delay)
+	}
+}
+
+// flakyProxy reset (or close if reset is false) the first failures connections then forward connections
+// to port, the number of connections received is returned by the function
+func flakyProxy(t *testing.T, port int, failures int, reset bool) (int, func() int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	connections := 0
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			connections++
+			rejected := connections <= failures
+			mu.Unlock()
+			if rejected {
+				if reset {
+					conn.(*net.TCPConn).SetLinger(0)
+				}
+				conn.Close()
+				continue
+			}
+			backend, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			go func() {
+				io.Copy(backend, conn)
+				backend.Close()
+			}()
+			go func() {
+				io.Copy(conn, backend)
+				conn.Close()
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return connections
+	}
+}
+
+func TestCiscoASA_sendCommandsRetry(t *testing.T) {
+	fake := &fakeASA{password: "secret", privileged: true}
+	port := fake.start(t)
+
+	// Two connections reset by the ASA then success
+	device := NewCiscoASA("asa")
+	device.Retries = 2
+	device.RetryDelay = 10 * time.Millisecond
+	proxy, _ := flakyProxy(t, port, 2, true)
+	if _, err := device.sendCommands("127.0.0.1", "icinga", "secret", "", proxy, nil); err != nil {
+		t.Errorf("Error sendCommands with retries: %s", err)
+	}
+
+	// Not enough retries, attempts are reported
+	device.Retries = 1
+	proxy, _ = flakyProxy(t, port, 2, true)
+	_, err := device.sendCommands("127.0.0.1", "icinga", "secret", "", proxy, nil)
+	if !errors.Is(err, errConnection) || !strings.HasSuffix(err.Error(), "(2 attempts)") {
+		t.Errorf("Error want %s after 2 attempts got %v", errConnection, err)
+	}
+
+	// Authentication failure is not retried
+	proxy, connections := flakyProxy(t, port, 0, true)
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "wrong", "", proxy, nil); !errors.Is(err, errAuthFailed) || connections() != 1 {
+		t.Errorf("Error want %s after 1 connection got %v after %d", errAuthFailed, err, connections())
+	}
+
+	// No retry if the delay exceed the session timeout
+	device.RetryDelay = time.Second
+	device.Timeout = 100 * time.Millisecond
+	proxy, _ = flakyProxy(t, port, 2, true)
+	start := time.Now()
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", proxy, nil); !errors.Is(err, errConnection) || time.Since(start) > time.Second {
+		t.Errorf("Error want %s before timeout got %v after %s", errConnection, err, time.Since(start))
+	}
+
+	// Connection closed without reset is not transient
+	device.Timeout = 0
+	device.RetryDelay = 10 * time.Millisecond
+	proxy, connections = flakyProxy(t, port, 2, false)
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", proxy, nil); !errors.Is(err, errConnection) || connections() != 1 {
+		t.Errorf("Error want %s after 1 connection got %v after %d", errConnection, err, connections())
+	}
+
+	// Connection refused is retried
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", closed, nil); !errors.Is(err, errConnection) || !strings.HasSuffix(err.Error(), "(2 attempts)") {
+		t.Errorf("Error want %s after 2 attempts got %v", errConnection, err)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("openSession, %w: dial tcp 10.0.0.1:22: connect: connection refused", errConnection), true},
+		{fmt.Errorf("openSession, %w: ssh: handshake failed: read tcp 10.0.0.2:5000->10.0.0.1:22: read: connection reset by peer", errConnection), true},
+		{fmt.Errorf("openSession, %w, Maximum number of SSH sessions reached", errConnection), true},
+		{fmt.Errorf("openSession, %w: dial tcp: lookup asa: no such host", errConnection), false},
+		{fmt.Errorf("openSession, %w: dial tcp 10.0.0.1:22: connect: no route to host", errConnection), false},
+		{fmt.Errorf("openSession, %w: ssh: handshake failed: EOF", errConnection), false},
+		{fmt.Errorf("openSession, %w for icinga@asa: connection refused", errAuthFailed), false},
+	}
+	for _, test := range tests {
+		if got := retryable(test.err); got != test.want {
+			t.Errorf("Error retryable(%s) want %t got %t", test.err, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// This file content the interactive SSH session used to send commands to CISCO ASA CLI,
+// privileged mode is entered with the enable password when the user land in user mode
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	// errEnableFailed is returned when the ASA reject the enable password
+	errEnableFailed = errors.New("enable authentication failed")
+	// errTimeout is returned when a step of the session didn't complete in time, the error name the step
+	errTimeout = errors.New("timeout")
+)
+
+var (
+	// rePromptEnd match the CLI prompt (user mode >, privileged mode #) at the end of the output
+	rePromptEnd = regexp.MustCompile(`(?:^|[\r\n])([\w\-./()@:]+[#>]) ?$`)
+	// rePasswordEnd match the password prompt of enable command at the end of the output
+	rePasswordEnd = regexp.MustCompile(`(?i)Password: ?$`)
+	// reMaxSessions match the message of a session rejected because too many sessions are open
+	reMaxSessions = regexp.MustCompile(`(?mi)^.*maximum.*sessions.*$`)
+)
+
+// cliSession is a shell opened on the ASA with a pseudo terminal
+type cliSession struct {
+	agent   net.Conn
+	jumps   []*ssh.Client // connections to jump hosts, in hop order
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	chunks  chan string
+	done    chan struct{} // closed when the session is closed, stop the output reader
+	pending string        // output not yet matched by expect
+	stdout  string        // whole session output
+	prompt  string        // last prompt found
+
+	ctx            context.Context // overall deadline of the session
+	commandTimeout time.Duration   // maximum wait for a prompt, no limit if 0
+}
+
+// openSession establish the SSH connection through jump hosts if any, open a shell and wait for the first
+// prompt, connection, SSH handshakes and shell opening must complete within the connect timeout
+func (asa *CiscoASA) openSession(ctx context.Context, host string, username string, password string, identity string, port int) (*cliSession, error) {
+	var err error
+
+	s := &cliSession{chunks: make(chan string), done: make(chan struct{}), ctx: ctx, commandTimeout: asa.CommandTimeout}
+
+	// Keys of SSH agent are tried first, agent connection is needed until the session is closed
+	var auths []ssh.AuthMethod
+	if asa.Agent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, fmt.Errorf("openSession, %w, SSH agent requested but SSH_AUTH_SOCK is not defined", errConfig)
+		}
+		if s.agent, err = net.Dial("unix", socket); err != nil {
+			return nil, fmt.Errorf("openSession, %w, unable to connect SSH agent: %s", errConfig, err)
+		}
+		auths = append(auths, ssh.PublicKeysCallback(agent.NewClient(s.agent).Signers))
+	}
+
+	// When host keys are verified, credentials are not sent to a jump host whose key can't be verified
+	if asa.KnownHosts != "" || asa.HostKey != "" || asa.TOFU {
+		for _, jump := range asa.Jumps {
+			if jump.HostKey == "" && asa.KnownHosts == "" && !asa.TOFU {
+				s.close()
+				return nil, fmt.Errorf("openSession, %w, key of jump host %s can't be verified without known hosts file or pinned fingerprint", errHostKeyUnknown, jump.address())
+			}
+		}
+	}
+
+	// The ASA is the last hop, connection is tunneled through jump hosts
+	hops := append(append([]JumpHost(nil), asa.Jumps...), JumpHost{Host: host, Port: port, Username: username, Password: password, Identity: identity})
+
+	// Deadline of connection steps is the connect timeout bounded by the overall deadline
+	address := hops[0].address()
+	deadline, ok := ctx.Deadline()
+	if asa.ConnectTimeout > 0 && (!ok || time.Now().Add(asa.ConnectTimeout).Before(deadline)) {
+		deadline = time.Now().Add(asa.ConnectTimeout)
+	}
+	dialCtx, cancel := ctx, context.CancelFunc(func() {})
+	if !deadline.IsZero() {
+		dialCtx, cancel = context.WithDeadline(ctx, deadline)
+	}
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(dialCtx, "tcp", address)
+	if err != nil {
+		s.close()
+		if dialCtx.Err() != nil {
+			return nil, fmt.Errorf("openSession, %w connecting to %s", errTimeout, address)
+		}
+		return nil, fmt.Errorf("openSession, %w: %s", errConnection, err)
+	}
+	// Deadline of the TCP connection also apply to the tunneled connections
+	base := conn
+	base.SetDeadline(deadline)
+
+	for i, hop := range hops {
+		address = hop.address()
+
+		// Host keys of ASA and jump hosts may be pinned
+		fingerprint := hop.HostKey
+		if i == len(hops)-1 {
+			fingerprint = asa.HostKey
+		}
+		var hostKeyErr error
+		hostKeyCallback, err := asa.hostKeyCallback(fingerprint, &hostKeyErr)
+		if err != nil {
+			base.Close()
+			s.close()
+			return nil, err
+		}
+
+		config := &ssh.ClientConfig{
+			User:            hop.Username,
+			Auth:            append(append([]ssh.AuthMethod(nil), auths...), authMethods(hop.Password, hop.Identity)...),
+			HostKeyCallback: hostKeyCallback,
+		}
+
+		c, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+		if err != nil {
+			base.Close()
+			s.close()
+			if hostKeyErr != nil {
+				return nil, fmt.Errorf("openSession, %w", hostKeyErr)
+			}
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return nil, fmt.Errorf("openSession, %w during SSH handshake with %s", errTimeout, address)
+			}
+			if strings.Contains(err.Error(), "unable to authenticate") {
+				return nil, fmt.Errorf("openSession, %w for %s@%s: %s", errAuthFailed, hop.Username, address, err)
+			}
+			return nil, fmt.Errorf("openSession, %w: %s", errConnection, err)
+		}
+		client := ssh.NewClient(c, chans, reqs)
+		if i == len(hops)-1 {
+			s.client = client
+			break
+		}
+
+		// Next hop is reached from the jump host
+		s.jumps = append(s.jumps, client)
+		if conn, err = client.Dial("tcp", hops[i+1].address()); err != nil {
+			base.Close()
+			s.close()
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return nil, fmt.Errorf("openSession, %w connecting to %s through %s", errTimeout, hops[i+1].address(), address)
+			}
+			return nil, fmt.Errorf("openSession, %w, jump host %s unable to reach %s: %s", errConnection, address, hops[i+1].address(), err)
+		}
+	}
+
+	if err = s.startShell(); err != nil {
+		s.close()
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return nil, fmt.Errorf("openSession, %w opening shell on %s", errTimeout, address)
+		}
+		return nil, err
+	}
+	base.SetDeadline(time.Time{})
+
+	if _, err = s.expect("waiting login prompt", rePromptEnd); err != nil {
+		s.close()
+		// Session closed by the ASA because the maximum number of SSH sessions is reached
+		if line := reMaxSessions.FindString(s.stdout); line != "" && errors.Is(err, errConnection) {
+			return nil, fmt.Errorf("openSession, %w, %s", errConnection, strings.TrimSpace(line))
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// startShell open a session with pseudo terminal and start the shell, output is read in background
+// so expect can wait for prompts
+func (s *cliSession) startShell() error {
+	var err error
+
+	if s.session, err = s.client.NewSession(); err != nil {
+		return fmt.Errorf("startShell, %w, error creating session on SSH server: %s", errConnection, err)
+	}
+	if err = s.session.RequestPty("xterm", 80, 40, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+		return fmt.Errorf("startShell, %w, request for pseudo terminal failed: %s", errConnection, err)
+	}
+	if s.stdin, err = s.session.StdinPipe(); err != nil {
+		return fmt.Errorf("startShell, %w, unable to setup stdin for session: %s", errConnection, err)
+	}
+	stdout, err := s.session.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("startShell, %w, unable to setup stdout for session: %s", errConnection, err)
+	}
+	if err = s.session.Shell(); err != nil {
+		return fmt.Errorf("startShell, %w, unable to start shell: %s", errConnection, err)
+	}
+
+	go func() {
+		buff := make([]byte, 4096)
+		for {
+			n, err := stdout.Read(buff)
+			if n > 0 {
+				select {
+				case s.chunks <- string(buff[:n]):
+				case <-s.done:
+					return
+				}
+			}
+			if err != nil {
+				close(s.chunks)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// authMethods return public key authentication if identity file is a valid private key and password authentication
+func authMethods(password string, identity string) []ssh.AuthMethod {
+	var auths []ssh.AuthMethod
+
+	// Replacing tilde char by real home directory
+	if home, err := user.Current(); err == nil {
+		identity = regexp.MustCompile(`^~(.*)$`).ReplaceAllString(identity, home.HomeDir+"${1}")
+	}
+	if key, err := ioutil.ReadFile(identity); err == nil {
+		if signer, err := ssh.ParsePrivateKey(key); err == nil {
+			auths = append(auths, ssh.PublicKeys(signer))
+		}
+	}
+	if password != "" {
+		auths = append(auths, ssh.Password(password))
+	}
+	return auths
+}
+
+// expect read output until one of patterns match the end of the output and return its index, step
+// describe what is awaited in timeout errors
+func (s *cliSession) expect(step string, patterns ...*regexp.Regexp) (int, error) {
+	var timeout <-chan time.Time
+	if s.commandTimeout > 0 {
+		timer := time.NewTimer(s.commandTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		for i, re := range patterns {
+			if m := re.FindStringSubmatch(s.pending); m != nil {
+				if re == rePromptEnd {
+					s.prompt = m[1]
+				}
+				s.pending = ""
+				return i, nil
+			}
+		}
+		select {
+		case chunk, ok := <-s.chunks:
+			if !ok {
+				return 0, fmt.Errorf("expect, %w, connection closed by the ASA %s", errConnection, step)
+			}
+			s.pending += chunk
+			s.stdout += chunk
+		case <-timeout:
+			return 0, fmt.Errorf("%w %s (%s)", errTimeout, step, s.commandTimeout)
+		case <-s.ctx.Done():
+			return 0, fmt.Errorf("%w, overall deadline reached %s", errTimeout, step)
+		}
+	}
+}
+
+// write send text to the shell
+func (s *cliSession) write(text string) error {
+	if _, err := s.stdin.Write([]byte(text)); err != nil {
+		return fmt.Errorf("write, %w, error sending data to the ASA: %s", errConnection, err)
+	}
+	return nil
+}
+
+// run send a command and wait for the next prompt
+func (s *cliSession) run(command string) error {
+	if err := s.write(command); err != nil {
+		return err
+	}
+	_, err := s.expect("waiting output of "+strings.TrimSpace(command), rePromptEnd)
+	return err
+}
+
+// enable enter privileged mode if the session is in user mode, the enable password is only sent
+// when the ASA ask it, a second password prompt or a user mode prompt mean the password was rejected
+func (s *cliSession) enable(password string) error {
+	if strings.HasSuffix(s.prompt, "#") {
+		return nil
+	}
+
+	if err := s.write("enable\n"); err != nil {
+		return err
+	}
+	i, err := s.expect("waiting enable password prompt", rePasswordEnd, rePromptEnd)
+	if err != nil {
+		return err
+	}
+	if i == 0 {
+		if err := s.write(password + "\n"); err != nil {
+			return err
+		}
+		if i, err = s.expect("waiting enable password check", rePasswordEnd, rePromptEnd); err != nil {
+			return err
+		}
+		if i == 0 {
+			return errEnableFailed
+		}
+	}
+	if !strings.HasSuffix(s.prompt, "#") {
+		return errEnableFailed
+	}
+	return nil
+}
+
+// close terminate the shell, the SSH connections and the SSH agent connection
+func (s *cliSession) close() {
+	close(s.done)
+	if s.session != nil {
+		s.session.Close()
+	}
+	if s.client != nil {
+		s.client.Close()
+	}
+	for i := len(s.jumps) - 1; i >= 0; i-- {
+		s.jumps[i].Close()
+	}
+	if s.agent != nil {
+		s.agent.Close()
+	}
+}
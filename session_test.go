@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// fakeASA is a minimal ASA CLI served over SSH for session tests
+type fakeASA struct {
+	password   string            // Login password
+	publicKey  ssh.PublicKey     // Authorized public key
+	enable     string            // Enable password
+	privileged bool              // User land directly in privileged mode
+	responses  map[string]string // Output of commands
+	hostKey    ssh.PublicKey     // Host key presented to clients, set by start
+	stalled    string            // Command printing its output without returning the prompt
+	forward    bool              // Forward TCP connections like a jump host
+
+	mu       sync.Mutex
+	received []string // Lines received, enable password excluded
+}
+
+// start listen on a random local port and serve SSH connections until test end
+func (f *fakeASA) start(t *testing.T) int {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != f.password {
+				return nil, errors.New("access denied")
+			}
+			return nil, nil
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if f.publicKey == nil || !bytes.Equal(key.Marshal(), f.publicKey.Marshal()) {
+				return nil, errors.New("unknown public key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+	f.hostKey = signer.PublicKey()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn, config)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// serve handle a SSH connection, only a shell with pseudo terminal and TCP forwarding of jump hosts are accepted
+func (f *fakeASA) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() == "direct-tcpip" && f.forward {
+			go f.forwardChannel(newChannel)
+			continue
+		}
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				req.Reply(req.Type == "pty-req" || req.Type == "shell", nil)
+				if req.Type == "shell" {
+					go f.cli(channel)
+				}
+			}
+		}()
+	}
+}
+
+// forwardChannel connect the channel to the requested address, the address is added to received lines
+func (f *fakeASA) forwardChannel(newChannel ssh.NewChannel) {
+	var target struct {
+		Host     string
+		Port     uint32
+		OrigHost string
+		OrigPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, "invalid forward request")
+		return
+	}
+	address := net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port)))
+	f.mu.Lock()
+	f.received = append(f.received, "forward "+address)
+	f.mu.Unlock()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	go func() {
+		io.Copy(conn, channel)
+		conn.Close()
+	}()
+	go func() {
+		io.Copy(channel, conn)
+		channel.Close()
+	}()
+}
+
+// cli emulate the ASA command line, user mode prompt is > and privileged mode prompt is #
+func (f *fakeASA) cli(channel ssh.Channel) {
+	defer channel.Close()
+
+	privileged := f.privileged
+	prompt := func() string {
+		if privileged {
+			return "asa# "
+		}
+		return "asa> "
+	}
+	fmt.Fprintf(channel, "Type help or '?' for a list of available commands.\r\n%s", prompt())
+
+	for {
+		line, err := readLine(channel)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.received = append(f.received, line)
+		f.mu.Unlock()
+		fmt.Fprintf(channel, "%s\r\n", line)
+
+		switch {
+		case line == "enable" && !privileged:
+			// ASA ask the password 3 times before denying access
+			for try := 0; try < 3 && !privileged; try++ {
+				fmt.Fprint(channel, "Password: ")
+				password, err := readLine(channel)
+				if err != nil {
+					return
+				}
+				fmt.Fprint(channel, "\r\n")
+				privileged = password == f.enable
+				if !privileged && try < 2 {
+					fmt.Fprint(channel, "Invalid password\r\n")
+				}
+			}
+			if !privileged {
+				fmt.Fprint(channel, "Access denied.\r\n")
+			}
+		case line == "exit":
+			return
+		case line == f.stalled:
+			fmt.Fprint(channel, strings.Replace(f.responses[line], "\n", "\r\n", -1))
+			continue
+		case f.responses[line] != "":
+			fmt.Fprint(channel, strings.Replace(f.responses[line], "\n", "\r\n", -1))
+		case strings.HasPrefix(line, "show ") && !privileged:
+			fmt.Fprint(channel, "ERROR: % Invalid input detected at '^' marker.\r\n")
+		}
+		fmt.Fprint(channel, prompt())
+	}
+}
+
+// readLine read a line terminated by \n or \r without echo
+func readLine(channel ssh.Channel) (string, error) {
+	var line []byte
+	buff := make([]byte, 1)
+	for {
+		if _, err := channel.Read(buff); err != nil {
+			return "", err
+		}
+		if buff[0] == '\n' || buff[0] == '\r' {
+			return string(line), nil
+		}
+		line = append(line, buff[0])
+	}
+}
+
+func TestCiscoASA_sendCommands(t *testing.T) {
+	fake := &fakeASA{password: "secret", enable: "enable-secret", responses: map[string]string{"show clock": "12:00:00.000 CEST Mon Jun 1 2020\n"}}
+	port := fake.start(t)
+
+	// User mode, enable password is sent when asked
+	device := NewCiscoASA("asa")
+	device.Enable = "enable-secret"
+	stdout, err := device.sendCommands("127.0.0.1", "icinga", "secret", "", port, []string{"show clock\n"})
+	if err != nil {
+		t.Fatalf("Error sendCommands: %s", err)
+	}
+	if output := strings.Join(commandOutput(stdout, "show clock"), "\n"); output != "12:00:00.000 CEST Mon Jun 1 2020" {
+		t.Errorf("Error unexpected output %q", output)
+	}
+	if strings.Contains(stdout, "enable-secret") {
+		t.Errorf("Error enable password in output %q", stdout)
+	}
+
+	// Enable password rejected
+	device.Enable = "wrong"
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, []string{"show clock\n"}); !errors.Is(err, errEnableFailed) {
+		t.Errorf("Error want %s got %v", errEnableFailed, err)
+	}
+
+	// Privileged user, enable is not sent
+	privileged := &fakeASA{password: "secret", privileged: true, responses: fake.responses}
+	port = privileged.start(t)
+	device.Enable = ""
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, []string{"show clock\n"}); err != nil {
+		t.Errorf("Error sendCommands: %s", err)
+	}
+	privileged.mu.Lock()
+	if len(privileged.received) == 0 || privileged.received[0] != "terminal pager 0" {
+		t.Errorf("Error unexpected commands %v", privileged.received)
+	}
+	privileged.mu.Unlock()
+
+	// Command rejected by the ASA, optional commands may be rejected
+	privileged.responses["show module sfr details"] = "ERROR: % Invalid input detected at '^' marker.\n"
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, []string{"show module sfr details\n", "show clock\n"}); err != nil {
+		t.Errorf("Error optional command rejected: %s", err)
+	}
+	privileged.responses["show cpu"] = "Command authorization failed\n"
+	_, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, []string{"show cpu\n", "show clock\n"})
+	if !errors.Is(err, errUnsupported) || !strings.HasSuffix(err.Error(), "show cpu => Command authorization failed") {
+		t.Errorf("Error want %s got %v", errUnsupported, err)
+	}
+
+	// Login failure
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "wrong", "", port, nil); !errors.Is(err, errAuthFailed) {
+		t.Errorf("Error want %s got %v", errAuthFailed, err)
+	}
+
+	// Connection refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port = listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); !errors.Is(err, errConnection) {
+		t.Errorf("Error want %s got %v", errConnection, err)
+	}
+}
+
+func TestCiscoASA_sendCommandsAgent(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeASA{publicKey: signer.PublicKey(), privileged: true}
+	port := fake.start(t)
+
+	// SSH agent holding the authorized key
+	dir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	keyring := agent.NewKeyring()
+	if err = keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+
+	device := NewCiscoASA("asa")
+	defer os.Setenv("SSH_AUTH_SOCK", os.Getenv("SSH_AUTH_SOCK"))
+	os.Setenv("SSH_AUTH_SOCK", socket)
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "", "", port, nil); err == nil {
+		t.Errorf("Error login without SSH agent succeeded")
+	}
+	device.Agent = true
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "", "", port, nil); err != nil {
+		t.Errorf("Error sendCommands with SSH agent: %s", err)
+	}
+}
+
+func TestCiscoASA_sendCommandsTimeout(t *testing.T) {
+	fake := &fakeASA{password: "secret", privileged: true, stalled: "show cpu", responses: map[string]string{
+		"show clock": "12:00:00.000 CEST Mon Jun 1 2020\n",
+		"show cpu":   "CPU utilization for 5 seconds = 1%; 1 minute: 2%; 5 minutes: 3%\n",
+	}}
+	port := fake.start(t)
+
+	// Command timeout, output received before the timeout is returned
+	device := NewCiscoASA("asa")
+	device.CommandTimeout = 200 * time.Millisecond
+	stdout, err := device.sendCommands("127.0.0.1", "icinga", "secret", "", port, []string{"show clock\n", "show cpu\n"})
+	if !errors.Is(err, errTimeout) || !strings.Contains(err.Error(), "show cpu") {
+		t.Errorf("Error want %s of show cpu got %v", errTimeout, err)
+	}
+	if !strings.Contains(stdout, "12:00:00.000") || !strings.Contains(stdout, "CPU utilization") {
+		t.Errorf("Error partial output missing %q", stdout)
+	}
+
+	// Overall timeout
+	device.CommandTimeout = 0
+	device.Timeout = 300 * time.Millisecond
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, []string{"show cpu\n"}); !errors.Is(err, errTimeout) || !strings.Contains(err.Error(), "overall") {
+		t.Errorf("Error want overall %s got %v", errTimeout, err)
+	}
+
+	// Server accepting TCP connection without SSH handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	device.Timeout = 0
+	device.ConnectTimeout = 200 * time.Millisecond
+	port = listener.Addr().(*net.TCPAddr).Port
+	if _, err = device.sendCommands("127.0.0.1", "icinga", "secret", "", port, nil); !errors.Is(err, errTimeout) || !strings.Contains(err.Error(), "handshake") {
+		t.Errorf("Error want handshake %s got %v", errTimeout, err)
+	}
+}
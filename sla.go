@@ -0,0 +1,171 @@
+// This file content implementation of methods to check CISCO ASA IP SLA operations
+// and route tracking objects
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckSLA check Cisco ASA SLA monitor operations and track objects
+func (asa *CiscoASA) CheckSLA(host string, username string, password string, identity string, port int, critical string, warning string, stateDir string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show sla monitor operational-state\n", "show track\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseSLA(stdout, stateFile(stateDir, host, "sla.json"), critical, warning)
+}
+
+// ParseSLA parse SLA operations and track objects and return Icinga result depending critical and warning
+// thresholds, track changes are compared with the previous run, only primary tracks of critical threshold
+// (all tracks by default) are critical when down
+func (asa *CiscoASA) ParseSLA(response string, file string, critical string, warning string) (ict.Icinga, error) {
+
+	var reEntry = regexp.MustCompile(`(?i)^\s*Entry number:\s*(?P<entry>\d+)\s*$`)
+	var reEntryField = regexp.MustCompile(`(?i)^\s*(?P<name>Timeout occurred|Latest RTT \(milliseconds\)|Latest operation return code):\s*(?P<value>.+?)\s*$`)
+	var reTrack = regexp.MustCompile(`(?i)^\s*Track\s+(?P<track>\d+)\s*$`)
+	var reReachability = regexp.MustCompile(`(?i)^\s*(?:Reachability|State|Line protocol) is (?P<state>\w+)\s*$`)
+	var reChanges = regexp.MustCompile(`(?i)^\s*(?P<changes>\d+) changes?, last change (?P<last>.+?)\s*$`)
+	var reTrackObject = regexp.MustCompile(`(?i)^\s*Response Time Reporter (?P<entry>\d+)`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for SLA entries and tracks, fields belong to the last entry or track found
+	var entries []map[string]string
+	var tracks []map[string]string
+	var current map[string]string
+	blocks := make(map[string][]string)
+	for _, line := range strings.Split(strings.Replace(response, "\r", "", -1), "\n") {
+		if m := reEntry.FindStringSubmatch(line); m != nil {
+			current = map[string]string{"entry": m[1]}
+			entries = append(entries, current)
+		} else if m := reTrack.FindStringSubmatch(line); m != nil {
+			current = map[string]string{"track": m[1], "changes": "0"}
+			tracks = append(tracks, current)
+		} else if current == nil {
+			continue
+		}
+		if current["track"] != "" && strings.TrimSpace(line) != "" {
+			blocks[current["track"]] = append(blocks[current["track"]], strings.TrimSpace(line))
+		}
+		if m := reEntryField.FindStringSubmatch(line); m != nil && current["entry"] != "" {
+			current[strings.ToLower(m[1])] = m[2]
+		} else if m := reTrackObject.FindStringSubmatch(line); m != nil && current["track"] != "" {
+			current["sla"] = m[1]
+		} else if m := reReachability.FindStringSubmatch(line); m != nil && current["track"] != "" {
+			current["state"] = m[1]
+		} else if m := reChanges.FindStringSubmatch(line); m != nil && current["track"] != "" {
+			current["changes"], current["last"] = m[1], m[2]
+		}
+	}
+
+	if len(entries) == 0 && len(tracks) == 0 {
+		return ict.Icinga{Message: "SLA monitor and track objects not found", Exit: ict.UnkExit}, nil
+	}
+
+	// A track without a parsed state is an unknown output format, not a track down
+	for _, track := range tracks {
+		if track["state"] == "" {
+			return ict.Icinga{}, fmt.Errorf("ParseSLA, %w, state of Track %s not found in show track => %s", errParse, track["track"], strings.Join(blocks[track["track"]], " / "))
+		}
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	// Only primary tracks raise critical state, other tracks are backup paths
+	primary := make(map[string]bool)
+	for _, track := range tracks {
+		primary[track["track"]] = errCritical != nil || len(criticalTH.PrimaryTracks) == 0
+	}
+	for _, number := range criticalTH.PrimaryTracks {
+		primary[strconv.Itoa(number)] = true
+	}
+
+	// A primary track down means the tracked path (usually primary ISP) is down
+	for _, track := range tracks {
+		if strings.ToLower(track["state"]) == "up" {
+			continue
+		}
+		if primary[track["track"]] {
+			condition = ict.CriExit
+		} else if condition < ict.WarExit {
+			condition = ict.WarExit
+		}
+		message = appendMessage(message, fmt.Sprintf("Track %s is %s", track["track"], track["state"]))
+	}
+
+	for _, entry := range entries {
+		returnCode := entry["latest operation return code"]
+		if strings.ToUpper(entry["timeout occurred"]) == "TRUE" || (returnCode != "" && strings.ToUpper(returnCode) != "OK") {
+			if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("SLA %s return code %s", entry["entry"], returnCode))
+		}
+		if rtt, err := strconv.Atoi(entry["latest rtt (milliseconds)"]); err == nil {
+			metrics += fmt.Sprintf("'SLA %s rtt'=%dms ", entry["entry"], rtt)
+		}
+		details += fmt.Sprintf("\nSLA %s: return code %s, rtt %s ms, timeout %s", entry["entry"], returnCode, entry["latest rtt (milliseconds)"], entry["timeout occurred"])
+	}
+
+	// Comparing track changes with the previous run, counters are reset on reload
+	changes := make(map[string]int)
+	for _, track := range tracks {
+		changes[track["track"]], _ = strconv.Atoi(track["changes"])
+	}
+	deltas, err := counterDeltas(file, changes)
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+	for _, track := range tracks {
+		count := changes[track["track"]]
+		if newChanges := deltas[track["track"]]; newChanges > 0 {
+			if errCritical == nil && primary[track["track"]] && criticalTH.TrackChanges > 0 && newChanges > criticalTH.TrackChanges {
+				condition = ict.CriExit
+				message = appendMessage(message, fmt.Sprintf("Track %s flapped %d times", track["track"], newChanges))
+			} else if errWarning != nil || newChanges > warningTH.TrackChanges || (errCritical == nil && criticalTH.TrackChanges > 0 && newChanges > criticalTH.TrackChanges) {
+				if condition < ict.WarExit {
+					condition = ict.WarExit
+				}
+				message = appendMessage(message, fmt.Sprintf("Track %s flapped %d times", track["track"], newChanges))
+			}
+		}
+		metrics += fmt.Sprintf("'Track %s changes'=%dc ", track["track"], count)
+		details += fmt.Sprintf("\nTrack %s (SLA %s): %s, %d changes, last change %s", track["track"], track["sla"], track["state"], count, track["last"])
+	}
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, entry := range entries {
+			log.Printf("SLA %s - %v\n", entry["entry"], entry)
+		}
+		for _, track := range tracks {
+			log.Printf("Track %s - %v\n", track["track"], track)
+		}
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("%d SLA operations and %d tracks Ok", len(entries), len(tracks))
+	}
+	return ict.Icinga{Message: message + details, Exit: condition, Metric: metrics}, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	SLAResponse = `asa# show sla monitor operational-state
+Entry number: 123
+Modification time: 13:59:37.111 UTC Tue Mar 15 2016
+Number of Octets Used by this Entry: 1480
+Number of operations attempted: 412
+Number of operations skipped: 0
+Current seconds left in Life: Forever
+Operational state of entry: Active
+Last time this entry was reset: Never
+Connection loss occurred: FALSE
+Timeout occurred: FALSE
+Over thresholds occurred: FALSE
+Latest RTT (milliseconds): 7
+Latest operation start time: 14:05:37.112 UTC Tue Mar 15 2016
+Latest operation return code: OK
+RTT Values:
+RTTAvg: 7       RTTMin: 7       RTTMax: 7
+NumOfRTT: 1     RTTSum: 7       RTTSum2: 49
+asa# show track
+Track 1
+  Response Time Reporter 123 reachability
+  Reachability is Up
+  2 changes, last change 00:45:12
+  Latest operation return code: OK
+  Latest RTT (millisecs) 7
+  Tracked by:
+    STATIC-IP-ROUTING 0
+asa# `
+)
+
+func TestCiscoASA_ParseSLA(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	file := filepath.Join(stateDir, "asa_sla.json")
+
+	icinga, err := asa.ParseSLA(SLAResponse, file, `{}`, `{}`)
+	if err != nil || icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.OkExit, icinga.Exit, icinga, err)
+	}
+	want := "1 SLA operations and 1 tracks Ok\nSLA 123: return code OK, rtt 7 ms, timeout FALSE\nTrack 1 (SLA 123): Up, 2 changes, last change 00:45:12"
+	if icinga.Message != want || icinga.Metric != "'SLA 123 rtt'=7ms 'Track 1 changes'=2c " {
+		t.Errorf("Error want\n%s\ngot\n%s", want, icinga)
+	}
+
+	// Primary path down after a new change
+	down := strings.Replace(SLAResponse, "Reachability is Up", "Reachability is Down", 1)
+	down = strings.Replace(down, "2 changes, last change 00:45:12", "3 changes, last change 00:00:12", 1)
+	down = strings.Replace(down, "Timeout occurred: FALSE", "Timeout occurred: TRUE", 1)
+	down = strings.Replace(down, "Latest operation return code: OK", "Latest operation return code: Timeout", 1)
+	icinga, _ = asa.ParseSLA(down, file, `{}`, `{}`)
+	if icinga.Exit != ict.CriExit || !strings.HasPrefix(icinga.Message, "Track 1 is Down/SLA 123 return code Timeout/Track 1 flapped 1 times\n") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Backup track down is a warning when primary tracks are given
+	icinga, _ = asa.ParseSLA(down, file, `{"primary_tracks":[2]}`, `{}`)
+	if icinga.Exit != ict.WarExit || !strings.HasPrefix(icinga.Message, "Track 1 is Down/SLA 123 return code Timeout\n") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+	icinga, _ = asa.ParseSLA(down, file, `{"primary_tracks":[1,2]}`, `{}`)
+	if icinga.Exit != ict.CriExit || !strings.HasPrefix(icinga.Message, "Track 1 is Down/SLA 123 return code Timeout\n") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Backup track flapping is a warning
+	flap := strings.Replace(down, "3 changes", "9 changes", 1)
+	icinga, _ = asa.ParseSLA(strings.Replace(flap, "Reachability is Down", "Reachability is Up", 1), file, `{"primary_tracks":[2],"track_changes":5}`, `{"track_changes":1}`)
+	if icinga.Exit != ict.WarExit || !strings.HasPrefix(icinga.Message, "SLA 123 return code Timeout/Track 1 flapped 6 times\n") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+
+	// A state line not understood is a parse error, not a track down
+	_, err = asa.ParseSLA(strings.Replace(SLAResponse, "Reachability is Up", "Reachability is Pending up", 1), file, `{}`, `{}`)
+	if !errors.Is(err, errParse) || !strings.Contains(err.Error(), "Track 1 not found in show track => Track 1 / Response Time Reporter 123 reachability / Reachability is Pending up") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+
+	icinga, _ = asa.ParseSLA("asa# ", file, `{}`, `{}`)
+	if icinga.Exit != ict.UnkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.UnkExit, icinga.Exit, icinga)
+	}
+}
+
+func TestCheck_SLA(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckSLA(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+	if err != nil {
+		t.Errorf("Error CheckSLA: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,390 @@
+// This file content the SNMP transport, status, vpnusers and failover checks read the Cisco MIBs
+// instead of sending commands on the CLI
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// SNMPConfig is the SNMP agent configuration of the ASA
+type SNMPConfig struct {
+	Version      string // SNMP version, 2c or 3
+	Port         int
+	Community    string // SNMPv2c community
+	Username     string // SNMPv3 security name
+	AuthProtocol string // SNMPv3 authentication protocol (MD5, SHA, SHA224, SHA256, SHA384, SHA512), none if empty
+	AuthPassword string
+	PrivProtocol string // SNMPv3 privacy protocol (DES, AES, AES192, AES256), none if empty
+	PrivPassword string
+	Timeout      time.Duration // Timeout of each request, gosnmp default if not set
+	Retries      int           // Retransmissions of a request
+}
+
+// OIDs of the Cisco MIBs tables read by SNMP checks
+const (
+	oidCPU5sec         = ".1.3.6.1.4.1.9.9.109.1.1.1.1.6"  // CISCO-PROCESS-MIB cpmCPUTotal5secRev
+	oidCPU1min         = ".1.3.6.1.4.1.9.9.109.1.1.1.1.7"  // CISCO-PROCESS-MIB cpmCPUTotal1minRev
+	oidCPU5min         = ".1.3.6.1.4.1.9.9.109.1.1.1.1.8"  // CISCO-PROCESS-MIB cpmCPUTotal5minRev
+	oidMemPoolName     = ".1.3.6.1.4.1.9.9.48.1.1.1.2"     // CISCO-MEMORY-POOL-MIB ciscoMemoryPoolName
+	oidMemPoolUsed     = ".1.3.6.1.4.1.9.9.48.1.1.1.5"     // CISCO-MEMORY-POOL-MIB ciscoMemoryPoolUsed
+	oidMemPoolFree     = ".1.3.6.1.4.1.9.9.48.1.1.1.6"     // CISCO-MEMORY-POOL-MIB ciscoMemoryPoolFree
+	oidEMPoolName      = ".1.3.6.1.4.1.9.9.221.1.1.1.1.3"  // CISCO-ENHANCED-MEMPOOL-MIB cempMemPoolName
+	oidEMPoolHCUsed    = ".1.3.6.1.4.1.9.9.221.1.1.1.1.18" // CISCO-ENHANCED-MEMPOOL-MIB cempMemPoolHCUsed
+	oidEMPoolHCFree    = ".1.3.6.1.4.1.9.9.221.1.1.1.1.20" // CISCO-ENHANCED-MEMPOOL-MIB cempMemPoolHCFree
+	oidSensorType      = ".1.3.6.1.4.1.9.9.91.1.1.1.1.1"   // CISCO-ENTITY-SENSOR-MIB entSensorType
+	oidSensorScale     = ".1.3.6.1.4.1.9.9.91.1.1.1.1.2"   // CISCO-ENTITY-SENSOR-MIB entSensorScale
+	oidSensorPrecision = ".1.3.6.1.4.1.9.9.91.1.1.1.1.3"   // CISCO-ENTITY-SENSOR-MIB entSensorPrecision
+	oidSensorValue     = ".1.3.6.1.4.1.9.9.91.1.1.1.1.4"   // CISCO-ENTITY-SENSOR-MIB entSensorValue
+	oidSensorStatus    = ".1.3.6.1.4.1.9.9.91.1.1.1.1.5"   // CISCO-ENTITY-SENSOR-MIB entSensorStatus
+	oidPhysicalName    = ".1.3.6.1.2.1.47.1.1.1.1.7"       // ENTITY-MIB entPhysicalName
+	oidVPNUsers        = ".1.3.6.1.4.1.9.9.392.1.3.3"      // CISCO-REMOTE-ACCESS-MONITOR-MIB crasNumUsers
+	oidHardwareValue   = ".1.3.6.1.4.1.9.147.1.2.1.1.1.3"  // CISCO-FIREWALL-MIB cfwHardwareStatusValue
+	oidHardwareDetail  = ".1.3.6.1.4.1.9.147.1.2.1.1.1.4"  // CISCO-FIREWALL-MIB cfwHardwareStatusDetail
+)
+
+// Values of entSensorType, entSensorStatus, cfwHardwareType (index of cfwHardwareStatusTable) and cfwHardwareStatusValue
+const (
+	sensorCelsius = 8
+	sensorRPM     = 10
+
+	hardwareFailoverLink = "4"
+	hardwarePrimary      = "6"
+	hardwareSecondary    = "7"
+
+	hardwareUp      = 2
+	hardwareActive  = 9
+	hardwareStandby = 10
+)
+
+var sensorStatus = map[int]string{1: "OK", 2: "Unavailable", 3: "Nonoperational"}
+var hardwareStatus = map[int]string{1: "Other", 2: "Up", 3: "Down", 4: "Error", 5: "Overtemp", 6: "Busy", 7: "No Media", 8: "Backup", 9: "Active", 10: "Standby"}
+
+// snmpClient return the SNMP client of host built from SNMP configuration
+func (asa *CiscoASA) snmpClient(ctx context.Context, host string) (*gosnmp.GoSNMP, error) {
+	client := &gosnmp.GoSNMP{
+		Target:             host,
+		Port:               uint16(asa.SNMP.Port),
+		Timeout:            asa.SNMP.Timeout,
+		Retries:            asa.SNMP.Retries,
+		ExponentialTimeout: true,
+		MaxOids:            gosnmp.MaxOids,
+		Context:            ctx,
+	}
+	if client.Port == 0 {
+		client.Port = 161
+	}
+	if client.Timeout <= 0 {
+		client.Timeout = gosnmp.Default.Timeout
+	}
+
+	switch asa.SNMP.Version {
+	case "2c", "2":
+		client.Version = gosnmp.Version2c
+		client.Community = asa.SNMP.Community
+	case "3":
+		client.Version = gosnmp.Version3
+		client.SecurityModel = gosnmp.UserSecurityModel
+		params := &gosnmp.UsmSecurityParameters{UserName: asa.SNMP.Username, AuthenticationProtocol: gosnmp.NoAuth, PrivacyProtocol: gosnmp.NoPriv}
+		client.MsgFlags = gosnmp.NoAuthNoPriv
+
+		if asa.SNMP.AuthProtocol != "" {
+			var found bool
+			for _, p := range []gosnmp.SnmpV3AuthProtocol{gosnmp.MD5, gosnmp.SHA, gosnmp.SHA224, gosnmp.SHA256, gosnmp.SHA384, gosnmp.SHA512} {
+				if strings.EqualFold(asa.SNMP.AuthProtocol, p.String()) {
+					params.AuthenticationProtocol, params.AuthenticationPassphrase, found = p, asa.SNMP.AuthPassword, true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("snmpClient, %w, unknown SNMPv3 authentication protocol %s", errConfig, asa.SNMP.AuthProtocol)
+			}
+			client.MsgFlags = gosnmp.AuthNoPriv
+		}
+		if asa.SNMP.PrivProtocol != "" {
+			if client.MsgFlags != gosnmp.AuthNoPriv {
+				return nil, fmt.Errorf("snmpClient, %w, SNMPv3 privacy protocol %s requires an authentication protocol", errConfig, asa.SNMP.PrivProtocol)
+			}
+			var found bool
+			for _, p := range []gosnmp.SnmpV3PrivProtocol{gosnmp.DES, gosnmp.AES, gosnmp.AES192, gosnmp.AES256} {
+				if strings.EqualFold(asa.SNMP.PrivProtocol, p.String()) {
+					params.PrivacyProtocol, params.PrivacyPassphrase, found = p, asa.SNMP.PrivPassword, true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("snmpClient, %w, unknown SNMPv3 privacy protocol %s", errConfig, asa.SNMP.PrivProtocol)
+			}
+			client.MsgFlags = gosnmp.AuthPriv
+		}
+		client.SecurityParameters = params
+	default:
+		return nil, fmt.Errorf("snmpClient, %w, unsupported SNMP version %s", errConfig, asa.SNMP.Version)
+	}
+	return client, nil
+}
+
+// snmpWalk return the values of the subtrees under roots, values are indexed by OID. As a wrong community is
+// silently ignored by the agent, it is reported as a timeout
+func (asa *CiscoASA) snmpWalk(host string, roots ...string) (map[string]gosnmp.SnmpPDU, error) {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if asa.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, asa.Timeout)
+	}
+	defer cancel()
+
+	client, err := asa.snmpClient(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.Connect(); err != nil {
+		return nil, fmt.Errorf("snmpWalk, %w: %s", errConnection, err)
+	}
+	defer client.Conn.Close()
+
+	values := make(map[string]gosnmp.SnmpPDU)
+	for _, root := range roots {
+		pdus, err := client.BulkWalkAll(root)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("snmpWalk, %w, overall deadline reached walking %s", errTimeout, root)
+			}
+			if strings.Contains(strings.ToLower(err.Error()), "timeout") {
+				return nil, fmt.Errorf("snmpWalk, %w waiting SNMP response of %s for %s (%s)", errTimeout, client.Target, root, err)
+			}
+			return nil, fmt.Errorf("snmpWalk, %w walking %s: %s", errConnection, root, err)
+		}
+		for _, pdu := range pdus {
+			if pdu.Type != gosnmp.NoSuchObject && pdu.Type != gosnmp.NoSuchInstance && pdu.Type != gosnmp.EndOfMibView {
+				values[pdu.Name] = pdu
+			}
+		}
+		if os.Getenv("VERBOSE") == "TRUE" {
+			log.Printf("%d values walked under %s", len(pdus), root)
+		}
+	}
+	return values, nil
+}
+
+// snmpColumn return the sorted row indexes of a table column and its values indexed by row index
+func snmpColumn(values map[string]gosnmp.SnmpPDU, column string) ([]string, map[string]gosnmp.SnmpPDU) {
+	var rows []string
+	cells := make(map[string]gosnmp.SnmpPDU)
+	for name, pdu := range values {
+		if strings.HasPrefix(name, column+".") {
+			index := strings.TrimPrefix(name, column+".")
+			rows = append(rows, index)
+			cells[index] = pdu
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return compareOID(rows[i], rows[j]) < 0 })
+	return rows, cells
+}
+
+// compareOID compare OIDs arc by arc
+func compareOID(a string, b string) int {
+	arcsA, arcsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(arcsA) && i < len(arcsB); i++ {
+		if arcsA[i] != arcsB[i] {
+			if len(arcsA[i]) != len(arcsB[i]) {
+				return len(arcsA[i]) - len(arcsB[i])
+			}
+			return strings.Compare(arcsA[i], arcsB[i])
+		}
+	}
+	return len(arcsA) - len(arcsB)
+}
+
+// snmpInt return the integer value of pdu
+func snmpInt(pdu gosnmp.SnmpPDU) int {
+	return int(gosnmp.ToBigInt(pdu.Value).Int64())
+}
+
+// snmpString return the string value of pdu
+func snmpString(pdu gosnmp.SnmpPDU) string {
+	if b, ok := pdu.Value.([]byte); ok {
+		return string(b)
+	}
+	if s, ok := pdu.Value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", pdu.Value)
+}
+
+// memoryPool return used and free bytes of the System memory pool of a memory pool table, first pool is
+// used if no pool is named System memory
+func memoryPool(values map[string]gosnmp.SnmpPDU, nameColumn string, usedColumn string, freeColumn string) (int, int, bool) {
+	pools, names := snmpColumn(values, nameColumn)
+	_, used := snmpColumn(values, usedColumn)
+	_, free := snmpColumn(values, freeColumn)
+	pool := ""
+	for _, p := range pools {
+		if _, found := free[p]; found && (pool == "" || strings.EqualFold(snmpString(names[p]), "System memory")) {
+			pool = p
+		}
+	}
+	if pool == "" {
+		return 0, 0, false
+	}
+	return snmpInt(used[pool]), snmpInt(free[pool]), true
+}
+
+// CheckStatusSNMP return the status of CPU usage, free memory, temperatures and fans read with SNMP. Values
+// are rendered like show environment, show cpu and show mem outputs so thresholds and messages are the same
+// as the SSH transport
+func (asa *CiscoASA) CheckStatusSNMP(host string, critical string, warning string) (ict.Icinga, error) {
+	values, err := asa.snmpWalk(host, oidCPU5sec, oidCPU1min, oidCPU5min, oidEMPoolName, oidEMPoolHCUsed, oidEMPoolHCFree,
+		oidMemPoolName, oidMemPoolUsed, oidMemPoolFree, oidSensorType, oidSensorScale, oidSensorPrecision, oidSensorValue, oidSensorStatus, oidPhysicalName)
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	// First CPU is the ASA CPU
+	cpus, cpu5sec := snmpColumn(values, oidCPU5sec)
+	_, cpu1min := snmpColumn(values, oidCPU1min)
+	_, cpu5min := snmpColumn(values, oidCPU5min)
+	if len(cpus) == 0 {
+		return ict.Icinga{}, fmt.Errorf("%w, cpmCPUTotal5secRev not found in CISCO-PROCESS-MIB", errParse)
+	}
+
+	// 32 bits pool counters saturate at 4 GB, they are only used if 64 bits counters are not available
+	used, free, found := memoryPool(values, oidEMPoolName, oidEMPoolHCUsed, oidEMPoolHCFree)
+	if !found {
+		used, free, found = memoryPool(values, oidMemPoolName, oidMemPoolUsed, oidMemPoolFree)
+	}
+	if !found {
+		return ict.Icinga{}, fmt.Errorf("%w, cempMemPoolHCFree and ciscoMemoryPoolFree not found in CISCO-ENHANCED-MEMPOOL-MIB and CISCO-MEMORY-POOL-MIB", errParse)
+	}
+	percent := 0
+	if free+used > 0 {
+		percent = int(math.Round(float64(free) * 100 / float64(free+used)))
+	}
+
+	response := "asa# show environment\n"
+	sensors, types := snmpColumn(values, oidSensorType)
+	_, scales := snmpColumn(values, oidSensorScale)
+	_, precisions := snmpColumn(values, oidSensorPrecision)
+	_, readings := snmpColumn(values, oidSensorValue)
+	_, statuses := snmpColumn(values, oidSensorStatus)
+	_, names := snmpColumn(values, oidPhysicalName)
+	var ambient, fan int
+	for _, sensor := range sensors {
+		status, found := sensorStatus[snmpInt(statuses[sensor])]
+		if !found {
+			status = "Unknown"
+		}
+		name := snmpString(names[sensor])
+		if name == "" {
+			name = "sensor " + sensor
+		}
+		// Value is entSensorValue * 10^(3 * (scale - units)) / 10^precision
+		value := float64(snmpInt(readings[sensor])) * math.Pow10(3*(snmpInt(scales[sensor])-9)-snmpInt(precisions[sensor]))
+
+		switch snmpInt(types[sensor]) {
+		case sensorCelsius:
+			ambient++
+			response += fmt.Sprintf("  Ambient %d: %.1f C - %s (%s)\n", ambient, value, status, name)
+		case sensorRPM:
+			fan++
+			response += fmt.Sprintf("  Cooling Fan %d: %d RPM - %s\n", fan, int(value), status)
+		}
+	}
+	response += "asa# show cpu\n"
+	response += fmt.Sprintf("CPU utilization for 5 seconds = %d%%; 1 minute: %d%%; 5 minutes: %d%%\n",
+		snmpInt(cpu5sec[cpus[0]]), snmpInt(cpu1min[cpus[0]]), snmpInt(cpu5min[cpus[0]]))
+	response += "asa# show mem\n"
+	response += fmt.Sprintf("Free memory:        %d bytes (%d%%)\n", free, percent)
+	response += "asa# "
+
+	if os.Getenv("VERBOSE") == "TRUE" {
+		log.Printf("SNMP values rendered as:\n%s", response)
+	}
+	return asa.ParseStatus(response, critical, warning)
+}
+
+// CheckVPNUsersSNMP return the number of VPN remote connected users read with SNMP
+func (asa *CiscoASA) CheckVPNUsersSNMP(host string, critical string, warning string) (ict.Icinga, error) {
+	values, err := asa.snmpWalk(host, oidVPNUsers)
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+	pdu, found := values[oidVPNUsers+".0"]
+	if !found {
+		return ict.Icinga{}, fmt.Errorf("%w, crasNumUsers not found in CISCO-REMOTE-ACCESS-MONITOR-MIB", errParse)
+	}
+	return vpnUsersResult(snmpInt(pdu), critical, warning), nil
+}
+
+// CheckFailoverSNMP return the failover status read with SNMP in cfwHardwareStatusTable, active time is not
+// available in CISCO-FIREWALL-MIB so failover_active thresholds are rejected
+func (asa *CiscoASA) CheckFailoverSNMP(host string, critical string, warning string) (ict.Icinga, error) {
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+	if (errCritical == nil && criticalTH.FailoverActive > 0) || (errWarning == nil && warningTH.FailoverActive > 0) {
+		return ict.Icinga{}, fmt.Errorf("CheckFailoverSNMP, %w, failover_active threshold can't be evaluated with SNMP transport, active time is not in CISCO-FIREWALL-MIB", errConfig)
+	}
+
+	values, err := asa.snmpWalk(host, oidHardwareValue, oidHardwareDetail)
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+	_, states := snmpColumn(values, oidHardwareValue)
+	_, details := snmpColumn(values, oidHardwareDetail)
+
+	primary, primaryFound := states[hardwarePrimary]
+	secondary, secondaryFound := states[hardwareSecondary]
+	if !primaryFound || !secondaryFound {
+		return ict.Icinga{}, fmt.Errorf("%w, failover units not found in CISCO-FIREWALL-MIB cfwHardwareStatusTable", errParse)
+	}
+
+	var condition = ict.OkExit
+	var message = ""
+
+	// Failover is Off when no unit is Active or Standby
+	inFailover := func(state int) bool { return state == hardwareActive || state == hardwareStandby }
+	if !inFailover(snmpInt(primary)) && !inFailover(snmpInt(secondary)) {
+		return ict.Icinga{Message: "Failover status not On", Exit: ict.CriExit}, nil
+	}
+
+	if link, found := states[hardwareFailoverLink]; found && snmpInt(link) != hardwareUp {
+		condition = ict.CriExit
+		detail := strings.TrimSpace(snmpString(details[hardwareFailoverLink]))
+		if detail == "" {
+			detail = hardwareStatus[snmpInt(link)]
+		}
+		message += fmt.Sprintf("Failover LAN Interface status %s", detail)
+	}
+
+	if snmpInt(primary) != hardwareActive && snmpInt(secondary) != hardwareActive {
+		if message != "" {
+			message += " / "
+		}
+		condition = ict.CriExit
+		message += "No active host (not possible)!!!"
+	}
+
+	if message != "" {
+		message += " / "
+	}
+	message += fmt.Sprintf("Primary host is %s, Secondary host is %s", hardwareState(primary), hardwareState(secondary))
+	return ict.Icinga{Message: message, Exit: condition}, nil
+}
+
+// hardwareState return the name of a cfwHardwareStatusValue
+func hardwareState(pdu gosnmp.SnmpPDU) string {
+	if state, found := hardwareStatus[snmpInt(pdu)]; found {
+		return state
+	}
+	return fmt.Sprintf("state %d", snmpInt(pdu))
+}
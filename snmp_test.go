@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// fakeAgent is a minimal SNMPv2c agent answering get, get-next and get-bulk requests for SNMP tests
+type fakeAgent struct {
+	community string           // Requests with another community are dropped
+	values    []gosnmp.SnmpPDU // MIB values, sorted by start
+}
+
+// start listen on a random local UDP port and answer requests until test end
+func (f *fakeAgent) start(t *testing.T) int {
+	sort.Slice(f.values, func(i, j int) bool { return compareOID(f.values[i].Name, f.values[j].Name) < 0 })
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buff := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buff)
+			if err != nil {
+				return
+			}
+			request, err := (&gosnmp.GoSNMP{Version: gosnmp.Version2c}).SnmpDecodePacket(buff[:n])
+			if err != nil || request.Community != f.community {
+				continue
+			}
+			response := &gosnmp.SnmpPacket{Version: gosnmp.Version2c, Community: f.community, PDUType: gosnmp.GetResponse, RequestID: request.RequestID}
+			for _, v := range request.Variables {
+				switch request.PDUType {
+				case gosnmp.GetRequest:
+					response.Variables = append(response.Variables, f.get(v.Name))
+				case gosnmp.GetNextRequest:
+					response.Variables = append(response.Variables, f.next(v.Name))
+				case gosnmp.GetBulkRequest:
+					repetitions := int(request.MaxRepetitions)
+					if repetitions == 0 {
+						repetitions = 10
+					}
+					name := v.Name
+					for i := 0; i < repetitions; i++ {
+						pdu := f.next(name)
+						response.Variables = append(response.Variables, pdu)
+						if pdu.Type == gosnmp.EndOfMibView {
+							break
+						}
+						name = pdu.Name
+					}
+				}
+			}
+			if out, err := response.MarshalMsg(); err == nil {
+				conn.WriteTo(out, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// get return the value of name
+func (f *fakeAgent) get(name string) gosnmp.SnmpPDU {
+	for _, v := range f.values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.NoSuchObject}
+}
+
+// next return the first value after name
+func (f *fakeAgent) next(name string) gosnmp.SnmpPDU {
+	for _, v := range f.values {
+		if compareOID(v.Name, name) > 0 {
+			return v
+		}
+	}
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.EndOfMibView}
+}
+
+// snmpDevice return a CiscoASA polling the fake agent on port with community
+func snmpDevice(port int, community string) *CiscoASA {
+	device := NewCiscoASA("asa")
+	device.SNMP = SNMPConfig{Version: "2c", Port: port, Community: community, Timeout: 500 * time.Millisecond}
+	device.Timeout = 5 * time.Second
+	return device
+}
+
+func TestCiscoASA_CheckStatusSNMP(t *testing.T) {
+	agent := &fakeAgent{community: "monitoring", values: []gosnmp.SnmpPDU{
+		{Name: oidCPU5sec + ".1", Type: gosnmp.Gauge32, Value: uint32(12)},
+		{Name: oidCPU1min + ".1", Type: gosnmp.Gauge32, Value: uint32(10)},
+		{Name: oidCPU5min + ".1", Type: gosnmp.Gauge32, Value: uint32(9)},
+		{Name: oidMemPoolName + ".1", Type: gosnmp.OctetString, Value: "System memory"},
+		{Name: oidMemPoolName + ".6", Type: gosnmp.OctetString, Value: "MEMPOOL_DMA"},
+		{Name: oidMemPoolUsed + ".1", Type: gosnmp.Gauge32, Value: uint32(1488756736)},
+		{Name: oidMemPoolUsed + ".6", Type: gosnmp.Gauge32, Value: uint32(1000)},
+		{Name: oidMemPoolFree + ".1", Type: gosnmp.Gauge32, Value: uint32(2806173696)},
+		{Name: oidMemPoolFree + ".6", Type: gosnmp.Gauge32, Value: uint32(9000)},
+		{Name: oidSensorType + ".10", Type: gosnmp.Integer, Value: sensorCelsius},
+		{Name: oidSensorType + ".11", Type: gosnmp.Integer, Value: sensorRPM},
+		{Name: oidSensorScale + ".10", Type: gosnmp.Integer, Value: 9},
+		{Name: oidSensorScale + ".11", Type: gosnmp.Integer, Value: 9},
+		{Name: oidSensorPrecision + ".10", Type: gosnmp.Integer, Value: 1},
+		{Name: oidSensorPrecision + ".11", Type: gosnmp.Integer, Value: 0},
+		{Name: oidSensorValue + ".10", Type: gosnmp.Integer, Value: 385},
+		{Name: oidSensorValue + ".11", Type: gosnmp.Integer, Value: 8064},
+		{Name: oidSensorStatus + ".10", Type: gosnmp.Integer, Value: 1},
+		{Name: oidSensorStatus + ".11", Type: gosnmp.Integer, Value: 1},
+		{Name: oidPhysicalName + ".10", Type: gosnmp.OctetString, Value: "Chassis Ambient Temperature"},
+		{Name: oidPhysicalName + ".11", Type: gosnmp.OctetString, Value: "Chassis Cooling Fan 1"},
+	}}
+	port := agent.start(t)
+	device := snmpDevice(port, "monitoring")
+
+	icinga, err := device.CheckStatusSNMP("127.0.0.1", `{"cpu":[90,70,50],"memory":20}`, `{"cpu":[70,50,30],"memory":30}`)
+	if err != nil {
+		t.Fatalf("Error CheckStatusSNMP: %s", err)
+	}
+	for _, metric := range []string{"'CPU usage [5s]'=12%", "'CPU usage [5m]'=9%", "'Free memory'=65%", "'Chassis Ambient Temperature [C]'=38.5", "'Fan 1 [RPM]'=8064"} {
+		if icinga.Exit != ict.OkExit || !strings.Contains(icinga.Metric, metric) {
+			t.Errorf("Error want %s in OK result got %v", metric, icinga)
+		}
+	}
+
+	// 64 bits counters of CISCO-ENHANCED-MEMPOOL-MIB are preferred, 32 bits counters saturate at 4 GB
+	hc := &fakeAgent{community: "monitoring", values: append([]gosnmp.SnmpPDU{
+		{Name: oidEMPoolName + ".1.1", Type: gosnmp.OctetString, Value: "System memory"},
+		{Name: oidEMPoolHCUsed + ".1.1", Type: gosnmp.Counter64, Value: uint64(6 << 30)},
+		{Name: oidEMPoolHCFree + ".1.1", Type: gosnmp.Counter64, Value: uint64(2 << 30)},
+	}, agent.values...)}
+	if icinga, err = snmpDevice(hc.start(t), "monitoring").CheckStatusSNMP("127.0.0.1", `{}`, `{}`); err != nil || !strings.Contains(icinga.Metric, "'Free memory'=25%") {
+		t.Errorf("Error want 25%% free memory got %v (%v)", icinga, err)
+	}
+
+	// Thresholds are the same as SSH transport
+	if icinga, err = device.CheckStatusSNMP("127.0.0.1", `{"cpu":[10,70,50]}`, `{}`); err != nil || icinga.Exit != ict.CriExit {
+		t.Errorf("Error want CRITICAL CPU usage got %v (%v)", icinga, err)
+	}
+
+	// CISCO-PROCESS-MIB not supported
+	empty := &fakeAgent{community: "monitoring"}
+	if _, err = snmpDevice(empty.start(t), "monitoring").CheckStatusSNMP("127.0.0.1", `{}`, `{}`); !errors.Is(err, errParse) {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+
+	// Requests with a wrong community are ignored by the agent
+	wrong := snmpDevice(port, "public")
+	if _, err = wrong.CheckStatusSNMP("127.0.0.1", `{}`, `{}`); !errors.Is(err, errTimeout) {
+		t.Errorf("Error want %s got %v", errTimeout, err)
+	}
+}
+
+func TestCiscoASA_CheckVPNUsersSNMP(t *testing.T) {
+	agent := &fakeAgent{community: "public", values: []gosnmp.SnmpPDU{
+		{Name: oidVPNUsers + ".0", Type: gosnmp.Gauge32, Value: uint32(3)},
+		{Name: ".1.3.6.1.4.1.9.9.392.1.3.4.0", Type: gosnmp.Gauge32, Value: uint32(12)},
+	}}
+	device := snmpDevice(agent.start(t), "public")
+
+	icinga, err := device.CheckVPNUsersSNMP("127.0.0.1", `{"users_vpn":5}`, `{"users_vpn":2}`)
+	if err != nil {
+		t.Fatalf("Error CheckVPNUsersSNMP: %s", err)
+	}
+	if icinga.Exit != ict.WarExit || icinga.Message != "3 VPN remote connected users > 2" || icinga.Metric != "'Active users'=3 " {
+		t.Errorf("Error unexpected result %v", icinga)
+	}
+}
+
+func TestCiscoASA_CheckFailoverSNMP(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    int
+		primary int
+		second  int
+		exit    int
+		message string
+	}{
+		{"active/standby", hardwareUp, hardwareActive, hardwareStandby, ict.OkExit, "Primary host is Active, Secondary host is Standby"},
+		{"failover off", hardwareUp, 3, 3, ict.CriExit, "Failover status not On"},
+		{"link down", 3, hardwareActive, hardwareStandby, ict.CriExit, "Failover LAN Interface status LAN failover interface down / Primary host is Active, Secondary host is Standby"},
+		{"no active unit", hardwareUp, hardwareStandby, 4, ict.CriExit, "No active host (not possible)!!! / Primary host is Standby, Secondary host is Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail := "LAN failover interface up"
+			if tt.link != hardwareUp {
+				detail = "LAN failover interface down"
+			}
+			agent := &fakeAgent{community: "public", values: []gosnmp.SnmpPDU{
+				{Name: oidHardwareValue + "." + hardwareFailoverLink, Type: gosnmp.Integer, Value: tt.link},
+				{Name: oidHardwareValue + "." + hardwarePrimary, Type: gosnmp.Integer, Value: tt.primary},
+				{Name: oidHardwareValue + "." + hardwareSecondary, Type: gosnmp.Integer, Value: tt.second},
+				{Name: oidHardwareDetail + "." + hardwareFailoverLink, Type: gosnmp.OctetString, Value: detail},
+				{Name: oidHardwareDetail + "." + hardwarePrimary, Type: gosnmp.OctetString, Value: "Active unit"},
+				{Name: oidHardwareDetail + "." + hardwareSecondary, Type: gosnmp.OctetString, Value: "Standby unit"},
+			}}
+			icinga, err := snmpDevice(agent.start(t), "public").CheckFailoverSNMP("127.0.0.1", `{}`, `{}`)
+			if err != nil {
+				t.Fatalf("Error CheckFailoverSNMP: %s", err)
+			}
+			if icinga.Exit != tt.exit || icinga.Message != tt.message {
+				t.Errorf("Error want %s (%d) got %v", tt.message, tt.exit, icinga)
+			}
+		})
+	}
+}
+
+func TestCiscoASA_CheckFailoverSNMPActiveTime(t *testing.T) {
+	// Threshold on active time can't be evaluated, the agent is never polled
+	device := snmpDevice(1, "public")
+	if _, err := device.CheckFailoverSNMP("127.0.0.1", `{"failover_active":900}`, `{}`); !errors.Is(err, errConfig) {
+		t.Errorf("Error want %s got %v", errConfig, err)
+	}
+}
+
+func TestCiscoASA_snmpClient(t *testing.T) {
+	// SNMPv3 isn't served by the fake agent, only security parameters are checked
+	device := NewCiscoASA("asa")
+	device.SNMP = SNMPConfig{Version: "3", Username: "icinga", AuthProtocol: "sha256", AuthPassword: "auth-secret", PrivProtocol: "AES", PrivPassword: "priv-secret"}
+	client, err := device.snmpClient(context.Background(), "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Error snmpClient: %s", err)
+	}
+	params := client.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if client.Version != gosnmp.Version3 || client.MsgFlags != gosnmp.AuthPriv || client.Port != 161 ||
+		params.UserName != "icinga" || params.AuthenticationProtocol != gosnmp.SHA256 || params.PrivacyProtocol != gosnmp.AES || params.PrivacyPassphrase != "priv-secret" {
+		t.Errorf("Error unexpected SNMPv3 client %+v %+v", client, params)
+	}
+
+	// SNMP timeout and retries are independent of SSH ones
+	device.ConnectTimeout, device.Retries = 10*time.Second, 5
+	device.SNMP = SNMPConfig{Version: "2c", Timeout: 2 * time.Second, Retries: 1}
+	if client, err = device.snmpClient(context.Background(), "127.0.0.1"); err != nil || client.Timeout != 2*time.Second || client.Retries != 1 {
+		t.Errorf("Error want timeout 2s and 1 retry got %+v (%v)", client, err)
+	}
+
+	for _, invalid := range []SNMPConfig{
+		{Version: "1"},
+		{Version: "3", Username: "icinga", AuthProtocol: "SHA1024"},
+		{Version: "3", Username: "icinga", PrivProtocol: "AES"},
+	} {
+		device.SNMP = invalid
+		if _, err = device.snmpClient(context.Background(), "127.0.0.1"); err == nil {
+			t.Errorf("Error invalid configuration %+v accepted", invalid)
+		}
+	}
+}
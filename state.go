@@ -0,0 +1,98 @@
+// This file content helpers to keep check states (baselines, previous counters) between
+// two plugin executions
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// stateFile return the path of the state file of a check for a host
+func stateFile(stateDir string, host string, name string) string {
+	reUnsafe := regexp.MustCompile(`[^\w.\-]`)
+	return filepath.Join(stateDir, fmt.Sprintf("%s_%s", reUnsafe.ReplaceAllString(host, "_"), name))
+}
+
+// readState return the content of a state file, os.IsNotExist(err) is true if state was never written
+func readState(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+// writeState write a state file only readable by the plugin user, state directory is created if needed
+func writeState(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("writeState, %w, unable to create state directory: %s", errConfig, err)
+	}
+
+	// Writing a temporary file first so a killed plugin never leave a truncated state
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writeState, %w, unable to write state file: %s", errConfig, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writeState, %w, unable to write state file: %s", errConfig, err)
+	}
+	return nil
+}
+
+// loadState decode a JSON state file in v, v is unchanged if state was never written
+func loadState(path string, v interface{}) error {
+	data, err := readState(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("loadState, %w, unable to read state file: %s", errConfig, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("loadState, %w, invalid state file %s: %s", errConfig, path, err)
+	}
+	return nil
+}
+
+// saveState encode v in a JSON state file
+func saveState(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("saveState, %w, unable to encode state: %s", errConfig, err)
+	}
+	return writeState(path, data)
+}
+
+// counterState is the state of checks comparing counters with the previous run, counters by name
+type counterState struct {
+	Counters map[string]int `json:"counters"`
+}
+
+// counterIncrease return the increase of a counter since previous value, a counter lower than previous
+// value was reset (reload, clear command) and its increase is its current value
+func counterIncrease(previous int, current int) int {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+// counterDeltas save current counters in file and return the increase of counters since the previous run,
+// counters not found in previous run (first run, new counter) have no delta
+func counterDeltas(file string, current map[string]int) (map[string]int, error) {
+	var state counterState
+
+	if err := loadState(file, &state); err != nil {
+		return nil, err
+	}
+	deltas := make(map[string]int)
+	for name, count := range current {
+		if previous, known := state.Counters[name]; known {
+			deltas[name] = counterIncrease(previous, count)
+		}
+	}
+	if err := saveState(file, counterState{Counters: current}); err != nil {
+		return nil, err
+	}
+	return deltas, nil
+}
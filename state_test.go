@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCounterDeltas(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	file := filepath.Join(stateDir, "asa_counters.json")
+
+	// First run has no delta
+	deltas, err := counterDeltas(file, map[string]int{"a": 10, "b": 5})
+	if err != nil || len(deltas) != 0 {
+		t.Errorf("Error want no delta got %v (%v)", deltas, err)
+	}
+
+	// Counter b was reset, counter c is new
+	deltas, err = counterDeltas(file, map[string]int{"a": 15, "b": 2, "c": 7})
+	if want := map[string]int{"a": 5, "b": 2}; err != nil || !reflect.DeepEqual(deltas, want) {
+		t.Errorf("Error want %v got %v (%v)", want, deltas, err)
+	}
+	deltas, err = counterDeltas(file, map[string]int{"a": 15, "c": 9})
+	if want := map[string]int{"a": 0, "c": 2}; err != nil || !reflect.DeepEqual(deltas, want) {
+		t.Errorf("Error want %v got %v (%v)", want, deltas, err)
+	}
+
+	// Invalid state file
+	if err = ioutil.WriteFile(file, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = counterDeltas(file, map[string]int{"a": 1}); !errors.Is(err, errConfig) {
+		t.Errorf("Error want %s got %v", errConfig, err)
+	}
+}
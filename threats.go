@@ -0,0 +1,155 @@
+// This file content implementation of methods to check CISCO ASA threat detection
+// rates and shunned hosts
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckThreats check Cisco ASA threat detection rates, top attacking hosts and shunned hosts
+func (asa *CiscoASA) CheckThreats(host string, username string, password string, identity string, port int, critical string, warning string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show threat-detection rate\n", "show threat-detection statistics top\n", "show shun\n"})
+	if err != nil {
+		// Rates are evaluated if top hosts or shunned hosts timed out
+		return partialResult(stdout, err, []string{"show threat-detection rate"}, func(r string) (ict.Icinga, error) {
+			return asa.ParseThreats(r, critical, warning)
+		})
+	}
+
+	return asa.ParseThreats(stdout, critical, warning)
+}
+
+// ParseThreats parse the output of threat detection commands and return Icinga result
+// depending critical and warning thresholds, an error is returned if no rate is found
+func (asa *CiscoASA) ParseThreats(response string, critical string, warning string) (ict.Icinga, error) {
+
+	var reRate = regexp.MustCompile(`(?mi)^\s*(?P<interval>\d+-(?:min|hour))\s+(?P<event>[a-z][a-z ]*?)\s*:\s+(?P<average>\d+)\s+(?P<current>\d+)\s+(?P<trigger>\d+)\s+(?P<total>\d+)\s*$`)
+	var reTopHost = regexp.MustCompile(`(?mi)^\s*(?P<interval>\d+-(?:min|hour))\s+(?P<event>[a-z][a-z ]*?)\s*:\s*(?P<host>\d{1,3}(?:\.\d{1,3}){3})\s+(?P<average>\d+)\s+(?P<current>\d+)\s+(?P<trigger>\d+)\s+(?P<total>\d+)\s*$`)
+	var reShun = regexp.MustCompile(`(?mi)^\s*shun\s+\((?P<interface>[^)]+)\)\s+(?P<source>\S+)\s+(?P<destination>\S+).*$`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	commands := []string{"show threat-detection rate", "show threat-detection statistics top", "show shun"}
+	if err := checkOutput(response, commands, section{"show threat-detection rate", "Threat detection rates", reRate}); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for threat detection rates
+	keys := reRate.SubexpNames()[1:]
+	var rates []map[string]string
+	for _, s := range reRate.FindAllStringSubmatch(response, -1) {
+		rate := make(map[string]string)
+		for i, v := range s[1:] {
+			rate[keys[i]] = v
+		}
+		rate["event"] = strings.Join(strings.Fields(rate["event"]), " ")
+		rates = append(rates, rate)
+	}
+
+	// Creating map for top attacking hosts
+	keys = reTopHost.SubexpNames()[1:]
+	var topHosts []map[string]string
+	for _, s := range reTopHost.FindAllStringSubmatch(response, -1) {
+		topHost := make(map[string]string)
+		for i, v := range s[1:] {
+			topHost[keys[i]] = v
+		}
+		topHost["event"] = strings.Join(strings.Fields(topHost["event"]), " ")
+		topHosts = append(topHosts, topHost)
+	}
+
+	// Creating map for shunned hosts
+	keys = reShun.SubexpNames()[1:]
+	var shunned []map[string]string
+	for _, s := range reShun.FindAllStringSubmatch(response, -1) {
+		shun := make(map[string]string)
+		for i, v := range s[1:] {
+			shun[keys[i]] = v
+		}
+		shunned = append(shunned, shun)
+	}
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	for _, rate := range rates {
+		average, _ := strconv.Atoi(rate["average"])
+		current, _ := strconv.Atoi(rate["current"])
+		name := fmt.Sprintf("%s %s", rate["interval"], rate["event"])
+
+		if errCritical == nil && len(criticalTH.ThreatRate) == 2 && (average > criticalTH.ThreatRate[0] || current > criticalTH.ThreatRate[1]) {
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("%s rate %d/%d eps > %d/%d", name, average, current, criticalTH.ThreatRate[0], criticalTH.ThreatRate[1]))
+		} else if errWarning == nil && len(warningTH.ThreatRate) == 2 && (average > warningTH.ThreatRate[0] || current > warningTH.ThreatRate[1]) {
+			if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("%s rate %d/%d eps > %d/%d", name, average, current, warningTH.ThreatRate[0], warningTH.ThreatRate[1]))
+		}
+
+		// Setting threat rate metrics
+		metrics += fmt.Sprintf("'%s [avg]'=%d ", name, average)
+		metrics += fmt.Sprintf("'%s [current]'=%d ", name, current)
+	}
+
+	// Any shunned host raise at least a warning
+	if len(shunned) > 0 {
+		if errCritical == nil && criticalTH.ShunnedHosts > 0 && len(shunned) > criticalTH.ShunnedHosts {
+			condition = ict.CriExit
+		} else if condition < ict.WarExit {
+			condition = ict.WarExit
+		}
+		var hosts []string
+		for _, shun := range shunned {
+			hosts = append(hosts, fmt.Sprintf("%s (%s)", shun["source"], shun["interface"]))
+		}
+		message = appendMessage(message, fmt.Sprintf("%d shunned hosts: %s", len(shunned), strings.Join(hosts, ", ")))
+	}
+	metrics += fmt.Sprintf("'Shunned hosts'=%d ", len(shunned))
+
+	// Listing top attacking hosts in long output
+	if len(topHosts) > 0 {
+		details = "Top attacking hosts:"
+		for _, topHost := range topHosts {
+			details += fmt.Sprintf("\n%s - %s %s avg %s eps, current %s eps, total %s events", topHost["host"], topHost["interval"], topHost["event"], topHost["average"], topHost["current"], topHost["total"])
+		}
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, rate := range rates {
+			log.Printf("%s %s - avg %s eps - current %s eps - trigger %s - total %s\n", rate["interval"], rate["event"], rate["average"], rate["current"], rate["trigger"], rate["total"])
+		}
+		for _, shun := range shunned {
+			log.Printf("Shunned %s -> %s on %s\n", shun["source"], shun["destination"], shun["interface"])
+		}
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("No threat detected (%d rates checked)", len(rates))
+	}
+	if details != "" {
+		message += "\n" + details
+	}
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
+}
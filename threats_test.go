@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	ThreatRateResponse = `asa# show threat-detection rate
+                          Average(eps)    Current(eps) Trigger      Total events
+  10-min ACL  drop:                  0               0       0               358
+  1-hour ACL  drop:                  0               0       0              2148
+  1-hour SYN attck:                  0               0       0                 8
+  10-min  Scanning:                 12              45       2              7410
+  1-hour  Scanning:                  3               0       0             12356
+  1-hour Bad  pkts:                  0               0       0                49
+  10-min  Firewall:                  0               0       0               358
+  1-hour  Firewall:                  0               0       0              2157
+  10-min DoS attck:                  0               0       0                 1
+  1-hour DoS attck:                  0               0       0                 5
+asa# show threat-detection statistics top
+Top 10 hosts statistics(in unit of packet per second)
+------------------------------------------------------
+                          Average(eps)    Current(eps) Trigger      Total events
+  1-hour Sent pkts  : 198.51.100.7      12              45       2              7410
+  1-hour Sent pkts  : 203.0.113.25       3               0       0             12356
+asa# show shun
+shun (outside) 198.51.100.7 0.0.0.0 0 0 0
+asa# `
+
+	ThreatRateCount = 10
+)
+
+func TestCiscoASA_ParseThreats(t *testing.T) {
+	icinga, err := asa.ParseThreats(ThreatRateResponse, `{"threat_rate":[100,500],"shunned_hosts":5}`, `{"threat_rate":[10,40]}`)
+	if err != nil {
+		t.Fatalf("Error ParseThreats: %s", err)
+	}
+	if icinga.Exit != ict.WarExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+	if !strings.Contains(icinga.Message, "10-min Scanning rate 12/45 eps > 10/40") {
+		t.Errorf("Error scanning rate not reported: %s", icinga.Message)
+	}
+	if !strings.Contains(icinga.Message, "1 shunned hosts: 198.51.100.7 (outside)") {
+		t.Errorf("Error shunned host not reported: %s", icinga.Message)
+	}
+	if !strings.Contains(icinga.Message, "\nTop attacking hosts:\n198.51.100.7 - 1-hour Sent pkts") {
+		t.Errorf("Error top attacking hosts not in long output: %s", icinga.Message)
+	}
+	if n := strings.Count(icinga.Metric, "[avg]"); n != ThreatRateCount {
+		t.Errorf("Error want %d rates got %d", ThreatRateCount, n)
+	}
+
+	icinga, _ = asa.ParseThreats(ThreatRateResponse, `{"threat_rate":[10,40],"shunned_hosts":0}`, `{}`)
+	if icinga.Exit != ict.CriExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Rejected command or missing rates are never a false OK
+	rejected := "asa# show threat-detection rate\n                  ^\nERROR: % Invalid input detected at '^' marker.\nasa# "
+	if _, err = asa.ParseThreats(rejected, `{}`, `{}`); !errors.Is(err, errUnsupported) {
+		t.Errorf("Error want %s got %v", errUnsupported, err)
+	}
+	disabled := "asa# show threat-detection rate\nBasic threat detection is disabled\nasa# show shun\nasa# "
+	if _, err = asa.ParseThreats(disabled, `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "Threat detection rates not found in show threat-detection rate => Basic threat detection is disabled") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
+
+func TestCiscoASA_CheckThreatsTimeout(t *testing.T) {
+	responses := make(map[string]string)
+	for _, command := range []string{"show threat-detection rate", "show threat-detection statistics top", "show shun"} {
+		responses[command] = strings.Join(commandOutput(ThreatRateResponse, command), "\n") + "\n"
+	}
+	fake := &fakeASA{password: "secret", privileged: true, stalled: "show shun", responses: responses}
+	port := fake.start(t)
+	device := NewCiscoASA("asa")
+	device.CommandTimeout = 200 * time.Millisecond
+
+	// Rates are evaluated when shunned hosts timed out, result is at least unknown
+	icinga, err := device.CheckThreats("127.0.0.1", "icinga", "secret", "", port, `{}`, `{}`)
+	if err != nil || icinga.Exit != ict.UnkExit || !strings.HasPrefix(icinga.Message, "Partial result, timeout waiting output of show shun") || strings.Count(icinga.Metric, "[avg]") != ThreatRateCount {
+		t.Errorf("Error unexpected partial result %v (%v)", icinga, err)
+	}
+	if strings.Contains(icinga.Message, "shunned") {
+		t.Errorf("Error output of timed out command evaluated: %s", icinga.Message)
+	}
+	if icinga, err = device.CheckThreats("127.0.0.1", "icinga", "secret", "", port, `{"threat_rate":[10,40]}`, `{}`); err != nil || icinga.Exit != ict.CriExit {
+		t.Errorf("Error want exit %d got %v (%v)", ict.CriExit, icinga, err)
+	}
+
+	// Nothing is evaluated when rates timed out
+	fake = &fakeASA{password: "secret", privileged: true, stalled: "show threat-detection rate", responses: responses}
+	port = fake.start(t)
+	if _, err = device.CheckThreats("127.0.0.1", "icinga", "secret", "", port, `{}`, `{}`); !errors.Is(err, errTimeout) {
+		t.Errorf("Error want %s got %v", errTimeout, err)
+	}
+}
+
+func TestCheck_Threats(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckThreats(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+	if err != nil {
+		t.Errorf("Error CheckThreats: %s", err)
+	}
+	t.Log(icinga)
+}
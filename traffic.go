@@ -0,0 +1,161 @@
+// This file content implementation of methods to check CISCO ASA interfaces throughput
+// and utilization
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// CheckTraffic check Cisco ASA interfaces 1 minute rates against interface speed
+func (asa *CiscoASA) CheckTraffic(host string, username string, password string, identity string, port int, critical string, warning string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show interface\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseTraffic(stdout, critical, warning)
+}
+
+// ParseTraffic parse 1 minute input and output rates of show interface and return Icinga result depending
+// utilization of named interfaces and critical and warning thresholds
+func (asa *CiscoASA) ParseTraffic(response string, critical string, warning string) (ict.Icinga, error) {
+
+	var reInterface = regexp.MustCompile(`(?i)^Interface (?P<interface>\S+) "(?P<nameif>[^"]*)", is (?P<status>.+?), line protocol is (?P<protocol>\S+)`)
+	var reSpeed = regexp.MustCompile(`(?i)\(?(?P<speed>\d+) (?P<unit>Kbps|Mbps|Gbps)\)?`)
+	var reBandwidth = regexp.MustCompile(`(?i)\bBW (?P<speed>\d+) (?P<unit>Kbps|Mbps|Gbps)`)
+	var reRate = regexp.MustCompile(`(?i)^\s*1 minute (?P<direction>input|output) rate (?P<pps>\d+) pkts/sec,\s*(?P<bytes>\d+) bytes/sec`)
+	var units = map[string]int{"kbps": 1000, "mbps": 1000000, "gbps": 1000000000}
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	commands := []string{"show interface"}
+	if err := checkOutput(response, commands); err != nil {
+		return ict.Icinga{}, err
+	}
+
+	//
+	// Parsing returned data
+	//
+	// Creating map for each interface, negotiated speed (Auto-Speed(1000 Mbps)) is preferred to bandwidth
+	// and only the first rates of the interface are kept (traffic statistics section repeat them)
+	var interfaces []map[string]string
+	var current map[string]string
+	for _, line := range strings.Split(strings.Replace(response, "\r", "", -1), "\n") {
+		if m := reInterface.FindStringSubmatch(line); m != nil {
+			current = map[string]string{"interface": m[1], "nameif": m[2], "status": m[3], "protocol": m[4]}
+			interfaces = append(interfaces, current)
+		} else if current == nil {
+			continue
+		} else if m := reRate.FindStringSubmatch(line); m != nil {
+			direction := strings.ToLower(m[1])
+			if _, found := current[direction+" pps"]; !found {
+				current[direction+" pps"], current[direction+" bytes"] = m[2], m[3]
+			}
+		} else if m := reBandwidth.FindStringSubmatch(line); m != nil && current["bandwidth"] == "" {
+			speed, _ := strconv.Atoi(m[1])
+			current["bandwidth"] = strconv.Itoa(speed * units[strings.ToLower(m[2])])
+		} else if strings.Contains(strings.ToLower(line), "duplex") && current["speed"] == "" {
+			if m := reSpeed.FindStringSubmatch(line); m != nil {
+				speed, _ := strconv.Atoi(m[1])
+				current["speed"] = strconv.Itoa(speed * units[strings.ToLower(m[2])])
+			}
+		}
+	}
+
+	if len(interfaces) == 0 {
+		return ict.Icinga{}, missingError(response, commands[0], "Interface")
+	}
+
+	// Interfaces without nameif don't carry traffic
+	var named []map[string]string
+	for _, intf := range interfaces {
+		if intf["nameif"] != "" {
+			named = append(named, intf)
+		}
+	}
+	if len(named) == 0 {
+		return ict.Icinga{Message: "Named interfaces not found", Exit: ict.UnkExit}, nil
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	for _, intf := range named {
+		name := intf["nameif"]
+		inPPS, _ := strconv.Atoi(intf["input pps"])
+		outPPS, _ := strconv.Atoi(intf["output pps"])
+		inBytes, _ := strconv.Atoi(intf["input bytes"])
+		outBytes, _ := strconv.Atoi(intf["output bytes"])
+		inBPS, outBPS := inBytes*8, outBytes*8
+
+		speed, _ := strconv.Atoi(intf["speed"])
+		if speed == 0 {
+			speed, _ = strconv.Atoi(intf["bandwidth"])
+		}
+
+		metrics += fmt.Sprintf("'%s in bps'=%d '%s out bps'=%d '%s in pps'=%d '%s out pps'=%d ", name, inBPS, name, outBPS, name, inPPS, name, outPPS)
+		details += fmt.Sprintf("\n%s (%s): in %s %d pps, out %s %d pps", name, intf["interface"], formatBPS(inBPS), inPPS, formatBPS(outBPS), outPPS)
+
+		// Utilization is computed on the busiest direction, speed is unknown on some subinterfaces
+		if speed == 0 {
+			continue
+		}
+		usage := float64(inBPS) * 100 / float64(speed)
+		if out := float64(outBPS) * 100 / float64(speed); out > usage {
+			usage = out
+		}
+		if errCritical == nil && criticalTH.TrafficUsage > 0 && usage >= float64(criticalTH.TrafficUsage) {
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("%s %.1f%% used >= %d%%", name, usage, criticalTH.TrafficUsage))
+		} else if errWarning == nil && warningTH.TrafficUsage > 0 && usage >= float64(warningTH.TrafficUsage) {
+			if condition < ict.WarExit {
+				condition = ict.WarExit
+			}
+			message = appendMessage(message, fmt.Sprintf("%s %.1f%% used >= %d%%", name, usage, warningTH.TrafficUsage))
+		}
+		metrics += fmt.Sprintf("'%s usage'=%.2f%%;;;0;100 ", name, usage)
+		details += fmt.Sprintf(", speed %s, %.1f%% used", formatBPS(speed), usage)
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		for _, intf := range interfaces {
+			log.Printf("Interface %s - %v\n", intf["interface"], intf)
+		}
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("%d interfaces Ok", len(named))
+	}
+	return ict.Icinga{Message: message + details, Exit: condition, Metric: metrics}, nil
+}
+
+// formatBPS format a rate in bits/sec with the most readable unit
+func formatBPS(bps int) string {
+	switch {
+	case bps >= 1000000000:
+		return fmt.Sprintf("%.1f Gbps", float64(bps)/1000000000)
+	case bps >= 1000000:
+		return fmt.Sprintf("%.1f Mbps", float64(bps)/1000000)
+	case bps >= 1000:
+		return fmt.Sprintf("%.1f Kbps", float64(bps)/1000)
+	}
+	return fmt.Sprintf("%d bps", bps)
+}
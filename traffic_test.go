@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	TrafficResponse = `asa# show interface
+Interface GigabitEthernet0/0 "outside", is up, line protocol is up
+  Hardware is i82574L rev00, BW 1000 Mbps, DLY 10 usec
+	Full-Duplex(Full-duplex), 100 Mbps(100 Mbps)
+	Input flow control is unsupported, output flow control is off
+	MAC address d0c2.8267.1234, MTU 1500
+	IP address 203.0.113.2, subnet mask 255.255.255.0
+	123456789 packets input, 98765432100 bytes, 0 no buffer
+	1 minute input rate 6000 pkts/sec,  7500000 bytes/sec
+	1 minute output rate 3000 pkts/sec,  1000000 bytes/sec
+	1 minute drop rate, 0 pkts/sec
+	5 minute input rate 5000 pkts/sec,  6000000 bytes/sec
+	5 minute output rate 2500 pkts/sec,  900000 bytes/sec
+	5 minute drop rate, 0 pkts/sec
+  Traffic Statistics for "outside":
+	123456000 packets input, 98765432000 bytes
+	1 minute input rate 5990 pkts/sec,  7490000 bytes/sec
+	1 minute output rate 2990 pkts/sec,  990000 bytes/sec
+	1 minute drop rate, 0 pkts/sec
+Interface GigabitEthernet0/1 "inside", is up, line protocol is up
+  Hardware is i82574L rev00, BW 1000 Mbps, DLY 10 usec
+	Auto-Duplex(Full-duplex), Auto-Speed(1000 Mbps)
+	IP address 10.1.1.1, subnet mask 255.255.255.0
+	1 minute input rate 3000 pkts/sec,  1000000 bytes/sec
+	1 minute output rate 6000 pkts/sec,  7500000 bytes/sec
+	1 minute drop rate, 0 pkts/sec
+Interface GigabitEthernet0/1.10 "guest", is up, line protocol is up
+  VLAN identifier 10
+	IP address 192.168.20.1, subnet mask 255.255.255.0
+	1 minute input rate 10 pkts/sec,  1250 bytes/sec
+	1 minute output rate 20 pkts/sec,  2500 bytes/sec
+	1 minute drop rate, 0 pkts/sec
+Interface GigabitEthernet0/3 "", is administratively down, line protocol is down
+  Hardware is i82574L rev00, BW 1000 Mbps, DLY 10 usec
+	Auto-Duplex, Auto-Speed
+	Available but not configured via nameif
+asa# `
+)
+
+func TestCiscoASA_ParseTraffic(t *testing.T) {
+	icinga, err := asa.ParseTraffic(TrafficResponse, `{"traffic_usage":90}`, `{"traffic_usage":50}`)
+	if err != nil {
+		t.Fatalf("Error ParseTraffic: %s", err)
+	}
+	if icinga.Exit != ict.WarExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+	want := "outside 60.0% used >= 50%\noutside (GigabitEthernet0/0): in 60.0 Mbps 6000 pps, out 8.0 Mbps 3000 pps, speed 100.0 Mbps, 60.0% used\ninside (GigabitEthernet0/1): in 8.0 Mbps 3000 pps, out 60.0 Mbps 6000 pps, speed 1.0 Gbps, 6.0% used\nguest (GigabitEthernet0/1.10): in 10.0 Kbps 10 pps, out 20.0 Kbps 20 pps"
+	if icinga.Message != want {
+		t.Errorf("Error want message\n%s\ngot\n%s", want, icinga.Message)
+	}
+	if !strings.HasPrefix(icinga.Metric, "'outside in bps'=60000000 'outside out bps'=8000000 'outside in pps'=6000 'outside out pps'=3000 'outside usage'=60.00%;;;0;100 ") {
+		t.Errorf("Error unexpected metrics %s", icinga.Metric)
+	}
+
+	icinga, _ = asa.ParseTraffic(TrafficResponse, `{"traffic_usage":50}`, `{}`)
+	if icinga.Exit != ict.CriExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Unrecognized output is an error with the offending line
+	if _, err = asa.ParseTraffic("asa# show interface\nNo interface configured\nasa# ", `{}`, `{}`); !errors.Is(err, errParse) || !strings.HasSuffix(err.Error(), "Interface not found in show interface => No interface configured") {
+		t.Errorf("Error want %s got %v", errParse, err)
+	}
+}
+
+func TestCheck_Traffic(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckTraffic(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning)
+	if err != nil {
+		t.Errorf("Error CheckTraffic: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,94 @@
+// This file content implementation of methods to check CISCO ASA running configuration
+// was saved to startup configuration
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// unsavedState is the state kept between two executions of the unsaved check
+type unsavedState struct {
+	Since time.Time `json:"since"`
+}
+
+// CheckUnsaved compare Cisco ASA running and startup configuration checksums
+func (asa *CiscoASA) CheckUnsaved(host string, username string, password string, identity string, port int, critical string, warning string, stateDir string) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show checksum\n", "show startup-config | include Cryptochecksum\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseUnsaved(stdout, stateFile(stateDir, host, "unsaved.json"), time.Now(), critical, warning)
+}
+
+// ParseUnsaved parse running and startup configuration checksums and return Icinga result depending
+// how long the running configuration is unsaved and critical and warning thresholds
+func (asa *CiscoASA) ParseUnsaved(response string, file string, now time.Time, critical string, warning string) (ict.Icinga, error) {
+
+	var reRunning = regexp.MustCompile(`(?mi)^Cryptochecksum:\s*(?P<checksum>(?:[0-9a-f]{8}\s*){4})\s*$`)
+	var reStartup = regexp.MustCompile(`(?mi)^Cryptochecksum:(?P<checksum>[0-9a-f]{32})\s*$`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+	var state unsavedState
+
+	//
+	// Parsing returned data
+	//
+	running := reRunning.FindStringSubmatch(strings.Join(commandOutput(response, "show checksum"), "\n"))
+	startup := reStartup.FindStringSubmatch(strings.Join(commandOutput(response, "show startup-config | include Cryptochecksum"), "\n"))
+	if running == nil || startup == nil {
+		return ict.Icinga{Message: "Running or startup configuration checksum not found", Exit: ict.UnkExit}, nil
+	}
+	runningChecksum := strings.Join(strings.Fields(strings.ToLower(running[1])), "")
+	startupChecksum := strings.ToLower(startup[1])
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		log.Printf("Running configuration checksum %s, startup configuration checksum %s\n", runningChecksum, startupChecksum)
+	}
+
+	// Configuration is saved, forgetting previous unsaved state
+	if runningChecksum == startupChecksum {
+		if err := saveState(file, unsavedState{}); err != nil {
+			return ict.Icinga{}, err
+		}
+		return ict.Icinga{Message: "Running configuration saved", Exit: ict.OkExit, Metric: "'Unsaved time'=0s "}, nil
+	}
+
+	// Keeping the first time running configuration was found unsaved
+	if err := loadState(file, &state); err != nil {
+		return ict.Icinga{}, err
+	}
+	if state.Since.IsZero() {
+		state.Since = now
+		if err := saveState(file, state); err != nil {
+			return ict.Icinga{}, err
+		}
+	}
+	unsaved := int(now.Sub(state.Since).Seconds())
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	// Without warning threshold any unsaved change raise a warning
+	var condition = ict.OkExit
+	if errCritical == nil && criticalTH.UnsavedTime > 0 && unsaved > criticalTH.UnsavedTime {
+		condition = ict.CriExit
+	} else if errWarning != nil || warningTH.UnsavedTime == 0 || unsaved > warningTH.UnsavedTime {
+		condition = ict.WarExit
+	}
+
+	message := fmt.Sprintf("Running configuration unsaved since %s (%s)", state.Since.Format("02 January 2006 15:04:05"), time.Duration(unsaved)*time.Second)
+	return ict.Icinga{Message: message, Exit: condition, Metric: fmt.Sprintf("'Unsaved time'=%ds ", unsaved)}, nil
+}
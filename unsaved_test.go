@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	UnsavedResponse = `asa# show checksum
+Cryptochecksum: 1a2b3c4d 5e6f7a8b 9c0d1e2f 3a4b5c6d
+asa# show startup-config | include Cryptochecksum
+Cryptochecksum:0123456789abcdef0123456789abcdef
+asa# `
+
+	SavedResponse = `asa# show checksum
+Cryptochecksum: 01234567 89abcdef 01234567 89abcdef
+asa# show startup-config | include Cryptochecksum
+Cryptochecksum:0123456789abcdef0123456789abcdef
+asa# `
+)
+
+func TestCiscoASA_ParseUnsaved(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	file := filepath.Join(stateDir, "asa_unsaved.json")
+	now := time.Date(2016, time.March, 15, 14, 0, 0, 0, time.UTC)
+
+	icinga, err := asa.ParseUnsaved(UnsavedResponse, file, now, `{"unsaved_time":7200}`, `{"unsaved_time":600}`)
+	if err != nil || icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.OkExit, icinga.Exit, icinga, err)
+	}
+
+	icinga, _ = asa.ParseUnsaved(UnsavedResponse, file, now.Add(20*time.Minute), `{"unsaved_time":7200}`, `{"unsaved_time":600}`)
+	if icinga.Exit != ict.WarExit || !strings.Contains(icinga.Message, "unsaved since 15 March 2016 14:00:00 (20m0s)") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+
+	icinga, _ = asa.ParseUnsaved(UnsavedResponse, file, now.Add(3*time.Hour), `{"unsaved_time":7200}`, `{"unsaved_time":600}`)
+	if icinga.Exit != ict.CriExit || icinga.Metric != "'Unsaved time'=10800s " {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// Saving configuration reset unsaved time
+	icinga, _ = asa.ParseUnsaved(SavedResponse, file, now.Add(4*time.Hour), `{"unsaved_time":7200}`, `{"unsaved_time":600}`)
+	if icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.OkExit, icinga.Exit, icinga)
+	}
+	icinga, _ = asa.ParseUnsaved(UnsavedResponse, file, now.Add(5*time.Hour), ``, ``)
+	if icinga.Exit != ict.WarExit || icinga.Metric != "'Unsaved time'=0s " {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+
+	icinga, _ = asa.ParseUnsaved("asa# ", file, now, ``, ``)
+	if icinga.Exit != ict.UnkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.UnkExit, icinga.Exit, icinga)
+	}
+}
+
+func TestCheck_Unsaved(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckUnsaved(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir)
+	if err != nil {
+		t.Errorf("Error CheckUnsaved: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,134 @@
+// This file content implementation of methods to check CISCO ASA uptime and detect
+// new crash information
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+// uptimeState is the state kept between two executions of the uptime check
+type uptimeState struct {
+	Crashinfo string `json:"crashinfo"`            // Hash of the last crash information found
+	Accepted  string `json:"accepted,omitempty"`   // Hash of the crash information accepted by the operator
+	FirstSeen int64  `json:"first_seen,omitempty"` // Time the crash information not accepted was first seen
+}
+
+// CheckUptime check Cisco ASA uptime, scheduled reload and crash information
+func (asa *CiscoASA) CheckUptime(host string, username string, password string, identity string, port int, critical string, warning string, stateDir string, accept bool) (ict.Icinga, error) {
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show version\n", "show reload\n", "show crashinfo\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseUptime(stdout, stateFile(stateDir, host, "uptime.json"), critical, warning, accept)
+}
+
+// ParseUptime parse uptime, scheduled reload and crash information and return Icinga result depending
+// critical and warning thresholds, a new crash information is critical until it is accepted or the crash_hold
+// critical threshold is elapsed
+func (asa *CiscoASA) ParseUptime(response string, file string, critical string, warning string, accept bool) (ict.Icinga, error) {
+
+	var reScheduled = regexp.MustCompile(`(?mi)^\s*Reload scheduled (?:for|in) (?P<when>.+?)\s*$`)
+	var reCrash = regexp.MustCompile(`(?mi)^\s*Thread Name:`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+	var state uptimeState
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+
+	//
+	// Parsing returned data
+	//
+	// Crash information embed a show version, uptime is only searched in show version output
+	uptime, found := parseUptime(strings.Join(commandOutput(response, "show version"), "\n"))
+	if !found {
+		return ict.Icinga{Message: "Uptime not found", Exit: ict.UnkExit}, nil
+	}
+
+	// Crash information is identified by its hash
+	crashinfo := ""
+	if output := strings.Join(commandOutput(response, "show crashinfo"), "\n"); reCrash.MatchString(output) {
+		crashinfo = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.TrimSpace(output))))
+	}
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	if errCritical == nil && criticalTH.Uptime > 0 && uptime < criticalTH.Uptime {
+		condition = ict.CriExit
+		message = appendMessage(message, fmt.Sprintf("Uptime %s < %s", time.Duration(uptime)*time.Second, time.Duration(criticalTH.Uptime)*time.Second))
+	} else if errWarning == nil && warningTH.Uptime > 0 && uptime < warningTH.Uptime {
+		condition = ict.WarExit
+		message = appendMessage(message, fmt.Sprintf("Uptime %s < %s", time.Duration(uptime)*time.Second, time.Duration(warningTH.Uptime)*time.Second))
+	} else {
+		message = appendMessage(message, fmt.Sprintf("Uptime %s", time.Duration(uptime)*time.Second))
+	}
+	metrics += fmt.Sprintf("'Uptime'=%ds ", uptime)
+
+	// Comparing crash information with the accepted one, crash information found on first run is accepted
+	_, errStat := os.Stat(file)
+	firstRun := os.IsNotExist(errStat)
+	if err := loadState(file, &state); err != nil {
+		return ict.Icinga{}, err
+	}
+	previous := state
+	// State of previous versions only record the last crash information
+	if state.Accepted == "" && state.FirstSeen == 0 {
+		state.Accepted = state.Crashinfo
+	}
+	if firstRun || accept {
+		state.Accepted = crashinfo
+	}
+	if crashinfo != state.Crashinfo || crashinfo == state.Accepted {
+		state.FirstSeen = 0
+	}
+	state.Crashinfo = crashinfo
+
+	now := time.Now()
+	if crashinfo != "" && crashinfo != state.Accepted {
+		if state.FirstSeen == 0 {
+			state.FirstSeen = now.Unix()
+		}
+		firstSeen := time.Unix(state.FirstSeen, 0)
+		if errCritical == nil && criticalTH.CrashHold > 0 && now.Sub(firstSeen) >= time.Duration(criticalTH.CrashHold)*time.Second {
+			state.Accepted, state.FirstSeen = crashinfo, 0
+		} else {
+			condition = ict.CriExit
+			message = appendMessage(message, fmt.Sprintf("New crash information found (show crashinfo) since %s", firstSeen.Format("02 January 2006 15:04:05")))
+		}
+	}
+	if crashinfo != "" && crashinfo == state.Accepted {
+		message = appendMessage(message, "Crash information present")
+	}
+	if firstRun || state != previous {
+		if err := saveState(file, state); err != nil {
+			return ict.Icinga{}, err
+		}
+	}
+
+	if scheduled := reScheduled.FindStringSubmatch(response); scheduled != nil {
+		message = appendMessage(message, fmt.Sprintf("Reload scheduled for %s", scheduled[1]))
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		log.Printf("Uptime %ds - crashinfo %s - accepted crashinfo %s\n", uptime, crashinfo, state.Accepted)
+	}
+
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	UptimeResponse = `asa# show version
+
+Cisco Adaptive Security Appliance Software Version 9.8(4)20 
+asa up 4 days 2 hours
+Hardware:   ASA5515, 8192 MB RAM, CPU Clarkdale 3058 MHz, 1 CPU (4 cores)
+asa# show reload
+Reload scheduled for 02:00:00 UTC Sun Mar 20 2016 (in 4 days 11 hours 54 minutes)
+asa# show crashinfo
+asa# `
+
+	CrashResponse = `asa# show version
+
+Cisco Adaptive Security Appliance Software Version 9.8(4)20 
+asa up 12 mins 3 secs
+asa# show reload
+No reload is scheduled.
+asa# show crashinfo
+: Saved_Crash
+
+Thread Name: DATAPATH-1-2010
+Page fault: Address not mapped
+
+Cisco Adaptive Security Appliance Software Version 9.8(4)20 
+asa up 45 days 3 hours
+asa# `
+)
+
+func TestCiscoASA_ParseUptime(t *testing.T) {
+	stateDir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(stateDir)
+	file := filepath.Join(stateDir, "asa_uptime.json")
+
+	icinga, err := asa.ParseUptime(UptimeResponse, file, `{"uptime":600}`, `{"uptime":3600}`, false)
+	if err != nil || icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s) %s", ict.OkExit, icinga.Exit, icinga, err)
+	}
+	if icinga.Message != "Uptime 98h0m0s/Reload scheduled for 02:00:00 UTC Sun Mar 20 2016 (in 4 days 11 hours 54 minutes)" || icinga.Metric != "'Uptime'=352800s " {
+		t.Errorf("Error unexpected result %s", icinga)
+	}
+
+	icinga, _ = asa.ParseUptime(CrashResponse, file, `{}`, `{"uptime":3600}`, false)
+	if icinga.Exit != ict.CriExit || !strings.Contains(icinga.Message, "Uptime 12m3s < 1h0m0s/New crash information found") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+
+	// New crash information stay critical until accepted
+	icinga, _ = asa.ParseUptime(CrashResponse, file, `{}`, `{}`, false)
+	if icinga.Exit != ict.CriExit || !strings.Contains(icinga.Message, "New crash information found") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+	icinga, _ = asa.ParseUptime(CrashResponse, file, `{}`, `{}`, true)
+	if icinga.Exit != ict.OkExit || !strings.Contains(icinga.Message, "Crash information present") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.OkExit, icinga.Exit, icinga)
+	}
+	icinga, _ = asa.ParseUptime(CrashResponse, file, `{}`, `{}`, false)
+	if icinga.Exit != ict.OkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.OkExit, icinga.Exit, icinga)
+	}
+
+	// New crash information is accepted when hold period is elapsed
+	if err = saveState(file, uptimeState{Crashinfo: "other", FirstSeen: time.Now().Add(-2 * time.Hour).Unix()}); err != nil {
+		t.Fatal(err)
+	}
+	if icinga, _ = asa.ParseUptime(CrashResponse, file, `{"crash_hold":86400}`, `{}`, false); icinga.Exit != ict.CriExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+	var state uptimeState
+	if err = loadState(file, &state); err != nil {
+		t.Fatal(err)
+	}
+	state.FirstSeen = time.Now().Add(-25 * time.Hour).Unix()
+	if err = saveState(file, state); err != nil {
+		t.Fatal(err)
+	}
+	if icinga, _ = asa.ParseUptime(CrashResponse, file, `{"crash_hold":86400}`, `{}`, false); icinga.Exit != ict.OkExit || !strings.Contains(icinga.Message, "Crash information present") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.OkExit, icinga.Exit, icinga)
+	}
+}
+
+func TestCheck_Uptime(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckUptime(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.stateDir, params.accept)
+	if err != nil {
+		t.Errorf("Error CheckUptime: %s", err)
+	}
+	t.Log(icinga)
+}
@@ -0,0 +1,275 @@
+// This file content implementation of methods to check CISCO ASA software version against
+// a local advisory file and detect recent unexpected reloads
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+	"gopkg.in/yaml.v2"
+)
+
+// VersionRange is an inclusive range of ASA software versions, empty bound is unlimited
+type VersionRange struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// Advisory is a vulnerability affecting some ASA software versions
+type Advisory struct {
+	ID       string         `json:"id" yaml:"id"`
+	Severity string         `json:"severity" yaml:"severity"`
+	Title    string         `json:"title,omitempty" yaml:"title,omitempty"`
+	Versions []VersionRange `json:"versions" yaml:"versions"`
+}
+
+// EndOfSupport is a range of ASA software versions no more supported since Date (YYYY-MM-DD)
+type EndOfSupport struct {
+	VersionRange `yaml:",inline"`
+	Date         string `json:"date,omitempty" yaml:"date,omitempty"`
+}
+
+// Advisories is the content of the advisory file
+type Advisories struct {
+	Advisories   []Advisory     `json:"advisories" yaml:"advisories"`
+	EndOfSupport []EndOfSupport `json:"end_of_support" yaml:"end_of_support"`
+}
+
+// CheckVersion check Cisco ASA software version against advisory file and recent reloads
+func (asa *CiscoASA) CheckVersion(host string, username string, password string, identity string, port int, critical string, warning string, advisory string) (ict.Icinga, error) {
+
+	var advisories *Advisories
+
+	if advisory != "" {
+		var err error
+		if advisories, err = loadAdvisories(advisory); err != nil {
+			return ict.Icinga{}, err
+		}
+	}
+
+	stdout, err := asa.sendCommands(host, username, password, identity, port, []string{"show version\n"})
+	if err != nil {
+		return ict.Icinga{}, err
+	}
+
+	return asa.ParseVersion(stdout, advisories, time.Now(), critical, warning), nil
+}
+
+// ParseVersion parse the output of show version and return Icinga result depending advisories,
+// critical and warning thresholds
+func (asa *CiscoASA) ParseVersion(response string, advisories *Advisories, now time.Time, critical string, warning string) ict.Icinga {
+
+	var reSoftware = regexp.MustCompile(`(?mi)^Cisco Adaptive Security Appliance Software Version\s+(?P<version>\S+)\s*(?:<.*>)?\s*$`)
+	var reASDM = regexp.MustCompile(`(?mi)^Device Manager Version\s+(?P<version>\S+)\s*$`)
+	var reHardware = regexp.MustCompile(`(?mi)^Hardware:\s+(?P<model>[^,\s]+).*$`)
+	var reSerial = regexp.MustCompile(`(?mi)^Serial Number:\s+(?P<serial>\S+)\s*$`)
+	var reReason = regexp.MustCompile(`(?mi)^\s*(?:Last )?reload reason\s*:\s*(?P<reason>.+?)\s*$`)
+	var reExpected = regexp.MustCompile(`(?i)(reload command|by user|upgrade)`)
+
+	var warningTH Threshold
+	var criticalTH Threshold
+
+	// Set exit condition depending status of all probes
+	var condition = ict.OkExit
+	var message = ""
+	var metrics = ""
+	var details = ""
+
+	//
+	// Parsing returned data
+	//
+	software := reSoftware.FindStringSubmatch(response)
+	if software == nil {
+		return ict.Icinga{Message: "ASA software version not found", Exit: ict.UnkExit}
+	}
+	version := software[1]
+	asdm, model, serial, reason := "", "", "", ""
+	if m := reASDM.FindStringSubmatch(response); m != nil {
+		asdm = m[1]
+	}
+	if m := reHardware.FindStringSubmatch(response); m != nil {
+		model = m[1]
+	}
+	if m := reSerial.FindStringSubmatch(response); m != nil {
+		serial = m[1]
+	}
+	if m := reReason.FindStringSubmatch(response); m != nil {
+		reason = m[1]
+	}
+	uptime, uptimeFound := parseUptime(response)
+
+	// Converting critical and warning threshold  JSON strings to Structured data
+	errCritical := json.Unmarshal([]byte(critical), &criticalTH)
+	errWarning := json.Unmarshal([]byte(warning), &warningTH)
+
+	// Checking running version against advisories
+	if advisories != nil {
+		var affected []string
+		for _, advisory := range advisories.Advisories {
+			for _, versions := range advisory.Versions {
+				if !versions.contains(version) {
+					continue
+				}
+				switch strings.ToLower(advisory.Severity) {
+				case "critical", "high":
+					condition = ict.CriExit
+				default:
+					if condition < ict.WarExit {
+						condition = ict.WarExit
+					}
+				}
+				affected = append(affected, advisory.ID)
+				details += fmt.Sprintf("\n%s (%s) %s", advisory.ID, advisory.Severity, advisory.Title)
+				break
+			}
+		}
+		if len(affected) > 0 {
+			message = appendMessage(message, fmt.Sprintf("Version %s affected by %s", version, strings.Join(affected, ", ")))
+		}
+		metrics += fmt.Sprintf("'Advisories'=%d ", len(affected))
+
+		for _, eos := range advisories.EndOfSupport {
+			if !eos.contains(version) {
+				continue
+			}
+			if eos.Date == "" {
+				if condition < ict.WarExit {
+					condition = ict.WarExit
+				}
+				message = appendMessage(message, fmt.Sprintf("Version %s is end of support", version))
+			} else if date, err := time.Parse("2006-01-02", eos.Date); err == nil && !now.Before(date) {
+				if condition < ict.WarExit {
+					condition = ict.WarExit
+				}
+				message = appendMessage(message, fmt.Sprintf("Version %s is end of support since %s", version, eos.Date))
+			}
+			break
+		}
+	}
+
+	// Checking recent reload not requested by a user
+	if uptimeFound {
+		if reason == "" || !reExpected.MatchString(reason) {
+			text := "Unexpected reload"
+			if reason != "" {
+				text += fmt.Sprintf(" (%s)", reason)
+			}
+			if errCritical == nil && criticalTH.ReloadUptime > 0 && uptime < criticalTH.ReloadUptime {
+				condition = ict.CriExit
+				message = appendMessage(message, fmt.Sprintf("%s %s ago", text, time.Duration(uptime)*time.Second))
+			} else if errWarning == nil && warningTH.ReloadUptime > 0 && uptime < warningTH.ReloadUptime {
+				if condition < ict.WarExit {
+					condition = ict.WarExit
+				}
+				message = appendMessage(message, fmt.Sprintf("%s %s ago", text, time.Duration(uptime)*time.Second))
+			}
+		}
+		metrics += fmt.Sprintf("'Uptime'=%ds ", uptime)
+	}
+
+	// Print log values if program is called in Test mode
+	if os.Getenv("VERBOSE") == "TRUE" {
+		log.Printf("Model %s - serial %s - version %s - ASDM %s - uptime %ds - reload reason %s\n", model, serial, version, asdm, uptime, reason)
+	}
+
+	summary := fmt.Sprintf("%s version %s", model, version)
+	if asdm != "" {
+		summary += fmt.Sprintf(" (ASDM %s)", asdm)
+	}
+	if serial != "" {
+		summary += fmt.Sprintf(" serial %s", serial)
+	}
+	if uptimeFound {
+		summary += fmt.Sprintf(" up %s", time.Duration(uptime)*time.Second)
+	}
+	message = appendMessage(message, strings.TrimSpace(summary))
+
+	if details != "" {
+		message += "\nAdvisories:" + details
+	}
+	return ict.Icinga{Message: message, Exit: condition, Metric: metrics}
+}
+
+// loadAdvisories read JSON or YAML (.yaml or .yml extension) advisory file
+func loadAdvisories(file string) (*Advisories, error) {
+	var advisories Advisories
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("loadAdvisories, %w, unable to read advisory file: %s", errConfig, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &advisories)
+	default:
+		err = json.Unmarshal(data, &advisories)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loadAdvisories, %w, invalid advisory file %s: %s", errConfig, file, err)
+	}
+	return &advisories, nil
+}
+
+// contains return true if version is in the range
+func (vr VersionRange) contains(version string) bool {
+	if vr.From != "" && compareVersions(version, vr.From) < 0 {
+		return false
+	}
+	if vr.To != "" && compareVersions(version, vr.To) > 0 {
+		return false
+	}
+	return true
+}
+
+// compareVersions compare two ASA versions like 9.8(4)20 or 9.12(3.9) and return -1, 0 or 1
+func compareVersions(a string, b string) int {
+	var reNumber = regexp.MustCompile(`\d+`)
+
+	na := reNumber.FindAllString(a, -1)
+	nb := reNumber.FindAllString(b, -1)
+	for i := 0; i < len(na) || i < len(nb); i++ {
+		va, vb := 0, 0
+		if i < len(na) {
+			va, _ = strconv.Atoi(na[i])
+		}
+		if i < len(nb) {
+			vb, _ = strconv.Atoi(nb[i])
+		}
+		if va < vb {
+			return -1
+		}
+		if va > vb {
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseUptime return the uptime in seconds of the "<hostname> up 1 year 2 days 3 hours" line of show version
+func parseUptime(response string) (int, bool) {
+	var reUptime = regexp.MustCompile(`(?mi)^\S+ up ((?:\d+ \w+[ \t]*)+)\s*$`)
+	var reUnit = regexp.MustCompile(`(?i)(\d+) (year|day|hour|min|sec)`)
+	var units = map[string]int{"year": 365 * 86400, "day": 86400, "hour": 3600, "min": 60, "sec": 1}
+
+	uptime := reUptime.FindStringSubmatch(response)
+	if uptime == nil {
+		return 0, false
+	}
+
+	seconds := 0
+	for _, unit := range reUnit.FindAllStringSubmatch(uptime[1], -1) {
+		n, _ := strconv.Atoi(unit[1])
+		seconds += n * units[strings.ToLower(unit[2])]
+	}
+	return seconds, true
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ict "github.com/tdh-foundation/icinga2-go-checktools"
+)
+
+const (
+	ShowVersionResponse = `asa# show version
+
+Cisco Adaptive Security Appliance Software Version 9.8(4)20 
+Firepower Extensible Operating System Version 2.2(2.97)
+Device Manager Version 7.12(1)
+
+Compiled on Wed 20-Feb-19 17:13 PST by builders
+System image file is "disk0:/asa984-20-smp-k8.bin"
+Config file at boot was "startup-config"
+
+asa up 2 hours 5 mins
+failover cluster up 1 year 12 days
+
+Hardware:   ASA5515, 8192 MB RAM, CPU Clarkdale 3058 MHz, 1 CPU (4 cores)
+ASA: 4096 MB RAM, 1 CPU (1 core)
+Internal ATA Compact Flash, 8192MB
+BIOS Flash MX25L6445E @ 0xffbb0000, 8192KB
+
+ 0: Int: Internal-Data0/0    : address is 0000.0000.0000, irq 11
+
+Licensed features for this platform:
+Maximum Physical Interfaces       : Unlimited      perpetual
+
+Serial Number: FCH1234ABCD
+Running Permanent Activation Key: 0x00000000 0x00000000 0x00000000 0x00000000 0x00000000 
+Configuration register is 0x1
+Image type          : Release
+Key Version         : A
+Configuration last modified by enable_15 at 14:05:22.102 UTC Tue Mar 15 2016
+asa# `
+
+	AdvisoriesYAML = `advisories:
+  - id: CVE-2020-3452
+    severity: high
+    title: Web Services Read-Only Path Traversal
+    versions:
+      - from: 9.8(1)
+        to: 9.8(4)20
+  - id: CVE-2021-1445
+    severity: medium
+    versions:
+      - from: 9.12(1)
+end_of_support:
+  - from: 9.8(1)
+    to: 9.8(99)
+    date: 2022-05-31
+`
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		want int
+	}{
+		{"9.8(4)20", "9.8(4)20", 0},
+		{"9.8(4)", "9.8(4)20", -1},
+		{"9.12(3)12", "9.8(4)20", 1},
+		{"9.8(4.25)", "9.8(4)25", 0},
+	} {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("Error compareVersions(%s, %s) want %d got %d", test.a, test.b, test.want, got)
+		}
+	}
+}
+
+func TestCiscoASA_ParseVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check_ciscoasa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "advisories.yaml")
+	ioutil.WriteFile(file, []byte(AdvisoriesYAML), 0600)
+	advisories, err := loadAdvisories(file)
+	if err != nil {
+		t.Fatalf("Error loading advisories: %s", err)
+	}
+	now := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	icinga := asa.ParseVersion(ShowVersionResponse, advisories, now, `{}`, `{"reload_uptime":3600}`)
+	if icinga.Exit != ict.CriExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.CriExit, icinga.Exit, icinga)
+	}
+	want := "Version 9.8(4)20 affected by CVE-2020-3452/Version 9.8(4)20 is end of support since 2022-05-31/ASA5515 version 9.8(4)20 (ASDM 7.12(1)) serial FCH1234ABCD up 2h5m0s\nAdvisories:\nCVE-2020-3452 (high) Web Services Read-Only Path Traversal"
+	if icinga.Message != want {
+		t.Errorf("Error want message\n%s\ngot\n%s", want, icinga.Message)
+	}
+	if icinga.Metric != "'Advisories'=1 'Uptime'=7500s " {
+		t.Errorf("Error unexpected metrics %s", icinga.Metric)
+	}
+
+	icinga = asa.ParseVersion(ShowVersionResponse, nil, now, `{}`, `{"reload_uptime":86400}`)
+	if icinga.Exit != ict.WarExit || !strings.HasPrefix(icinga.Message, "Unexpected reload 2h5m0s ago") {
+		t.Errorf("Error want exit %d got %d (%s)", ict.WarExit, icinga.Exit, icinga)
+	}
+
+	icinga = asa.ParseVersion("asa# ", nil, now, `{}`, `{}`)
+	if icinga.Exit != ict.UnkExit {
+		t.Errorf("Error want exit %d got %d (%s)", ict.UnkExit, icinga.Exit, icinga)
+	}
+}
+
+func TestCheck_Version(t *testing.T) {
+	skipOnline(t)
+	icinga, err := asa.CheckVersion(params.host, params.username, params.password, params.identity, params.port, params.critical, params.warning, params.advisory)
+	if err != nil {
+		t.Errorf("Error CheckVersion: %s", err)
+	}
+	t.Log(icinga)
+}